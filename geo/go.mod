@@ -0,0 +1,20 @@
+module github.com/nibbleshift/mod2blob/geo
+
+go 1.22.2
+
+require (
+	github.com/paulmach/orb v0.13.0
+	github.com/spatial-go/geoos v1.1.3
+	github.com/tidwall/geojson v1.4.5
+	github.com/uber/h3-go/v4 v4.1.0
+)
+
+require (
+	github.com/tidwall/geoindex v1.4.4 // indirect
+	github.com/tidwall/gjson v1.12.1 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.0 // indirect
+	github.com/tidwall/rtree v1.3.1 // indirect
+	github.com/tidwall/sjson v1.2.4 // indirect
+	go.mongodb.org/mongo-driver/v2 v2.5.0 // indirect
+)
@@ -0,0 +1,217 @@
+// Package h3json converts between bloblang values and the types of
+// github.com/uber/h3-go/v4. Cells are exchanged as their hex string,
+// coordinates and loops as GeoJSON points and polygons. It is the
+// adapter package mod2blob uses for h3 based modules.
+package h3json
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nibbleshift/mod2blob/geo/internal/codec"
+	"github.com/uber/h3-go/v4"
+)
+
+type position [2]float64
+
+// geometry is the subset of a GeoJSON geometry h3 needs
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+func decode(v any, coordinates any, types ...string) (string, error) {
+	raw, err := codec.Geometry(v)
+	if err != nil {
+		return "", err
+	}
+
+	g := geometry{}
+
+	err = json.Unmarshal(raw, &g)
+	if err != nil {
+		return "", fmt.Errorf("invalid geojson: %w", err)
+	}
+
+	for _, t := range types {
+		if g.Type == t {
+			return g.Type, json.Unmarshal(g.Coordinates, coordinates)
+		}
+	}
+	return "", fmt.Errorf("expected a GeoJSON %s, got %q", types[0], g.Type)
+}
+
+func encode(typ string, coordinates any) (any, error) {
+	return codec.Marshal(map[string]any{"type": typ, "coordinates": coordinates})
+}
+
+// ToCell accepts a cell as its hex string or as an integer. Floats
+// are refused, as they can't hold every cell exactly.
+func ToCell(v any) (h3.Cell, error) {
+	var c h3.Cell
+
+	switch t := v.(type) {
+	case string:
+		c = h3.Cell(h3.IndexFromString(t))
+	case int64:
+		c = h3.Cell(t)
+	case uint64:
+		c = h3.Cell(t)
+	case float64:
+		return 0, fmt.Errorf("h3 cell %v is a float, which can't hold a cell exactly, pass its hex string instead", t)
+	case json.Number:
+		n, err := t.Int64()
+		if err != nil {
+			return 0, err
+		}
+		c = h3.Cell(n)
+	default:
+		return 0, fmt.Errorf("expected an h3 cell, got %T", v)
+	}
+
+	if !c.IsValid() {
+		return 0, fmt.Errorf("invalid h3 cell %v", v)
+	}
+	return c, nil
+}
+
+// FromCell returns the hex string of c
+func FromCell(c h3.Cell) (any, error) {
+	return c.String(), nil
+}
+
+// ToCells accepts an array of cells
+func ToCells(v any) ([]h3.Cell, error) {
+	values, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array of h3 cells, got %T", v)
+	}
+
+	cells := make([]h3.Cell, 0, len(values))
+	for i, value := range values {
+		c, err := ToCell(value)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		cells = append(cells, c)
+	}
+	return cells, nil
+}
+
+// FromCells returns the hex strings of cells
+func FromCells(cells []h3.Cell) (any, error) {
+	values := make([]any, 0, len(cells))
+	for _, c := range cells {
+		values = append(values, c.String())
+	}
+	return values, nil
+}
+
+// ToLatLng accepts a GeoJSON Point
+func ToLatLng(v any) (h3.LatLng, error) {
+	p := position{}
+
+	_, err := decode(v, &p, "Point")
+	if err != nil {
+		return h3.LatLng{}, err
+	}
+	return h3.NewLatLng(p[1], p[0]), nil
+}
+
+// FromLatLng returns ll as a GeoJSON Point
+func FromLatLng(ll h3.LatLng) (any, error) {
+	return encode("Point", position{ll.Lng, ll.Lat})
+}
+
+func toLoop(ring []position) h3.GeoLoop {
+	// GeoJSON rings repeat their first position, h3 loops don't
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+
+	loop := make(h3.GeoLoop, 0, len(ring))
+	for _, p := range ring {
+		loop = append(loop, h3.NewLatLng(p[1], p[0]))
+	}
+	return loop
+}
+
+func fromLoop(loop []h3.LatLng) []position {
+	ring := make([]position, 0, len(loop)+1)
+	for _, ll := range loop {
+		ring = append(ring, position{ll.Lng, ll.Lat})
+	}
+
+	if len(ring) > 0 {
+		ring = append(ring, ring[0])
+	}
+	return ring
+}
+
+// ToGeoLoop accepts a GeoJSON Polygon, of which only the outer ring is
+// used, or a LineString
+func ToGeoLoop(v any) (h3.GeoLoop, error) {
+	var coordinates json.RawMessage
+
+	typ, err := decode(v, &coordinates, "Polygon", "LineString")
+	if err != nil {
+		return nil, err
+	}
+
+	if typ == "LineString" {
+		ring := []position{}
+		if err := json.Unmarshal(coordinates, &ring); err != nil {
+			return nil, err
+		}
+		return toLoop(ring), nil
+	}
+
+	rings := [][]position{}
+	if err := json.Unmarshal(coordinates, &rings); err != nil {
+		return nil, err
+	}
+
+	if len(rings) == 0 {
+		return h3.GeoLoop{}, nil
+	}
+	return toLoop(rings[0]), nil
+}
+
+// FromGeoLoop returns loop as a GeoJSON Polygon
+func FromGeoLoop(loop h3.GeoLoop) (any, error) {
+	return encode("Polygon", [][]position{fromLoop(loop)})
+}
+
+// FromCellBoundary returns the boundary of a cell as a GeoJSON Polygon
+func FromCellBoundary(b h3.CellBoundary) (any, error) {
+	return encode("Polygon", [][]position{fromLoop(b)})
+}
+
+// ToGeoPolygon accepts a GeoJSON Polygon
+func ToGeoPolygon(v any) (h3.GeoPolygon, error) {
+	rings := [][]position{}
+
+	_, err := decode(v, &rings, "Polygon")
+	if err != nil {
+		return h3.GeoPolygon{}, err
+	}
+
+	polygon := h3.GeoPolygon{}
+	for i, ring := range rings {
+		if i == 0 {
+			polygon.GeoLoop = toLoop(ring)
+			continue
+		}
+		polygon.Holes = append(polygon.Holes, toLoop(ring))
+	}
+	return polygon, nil
+}
+
+// FromGeoPolygon returns p as a GeoJSON Polygon
+func FromGeoPolygon(p h3.GeoPolygon) (any, error) {
+	rings := [][]position{fromLoop(p.GeoLoop)}
+	for _, hole := range p.Holes {
+		rings = append(rings, fromLoop(hole))
+	}
+	return encode("Polygon", rings)
+}
@@ -0,0 +1,73 @@
+package h3json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+const cell = "872830828ffffff"
+
+func parse(t *testing.T, s string) any {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestToCell(t *testing.T) {
+	want := h3.Cell(h3.IndexFromString(cell))
+
+	for _, input := range []any{cell, int64(want), uint64(want), json.Number(strconv.FormatInt(int64(want), 10))} {
+		c, err := ToCell(input)
+		if err != nil || c != want {
+			t.Errorf("%#v: got %v, %v", input, c, err)
+		}
+	}
+
+	for _, input := range []any{float64(want), "zzz", true} {
+		if _, err := ToCell(input); err == nil {
+			t.Errorf("%#v: expected an error", input)
+		}
+	}
+
+	if got, _ := FromCell(want); got != cell {
+		t.Errorf("FromCell: got %v", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	point := `{"type":"Point","coordinates":[-122.4,37.8]}`
+
+	ll, err := ToLatLng(parse(t, point))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := FromLatLng(ll); !reflect.DeepEqual(got, parse(t, point)) {
+		t.Errorf("point: got %v", got)
+	}
+
+	polygon := `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]],[[0.2,0.1],[0.3,0.1],[0.3,0.2],[0.2,0.1]]]}`
+
+	p, err := ToGeoPolygon(parse(t, polygon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.GeoLoop) != 3 || len(p.Holes) != 1 {
+		t.Errorf("polygon: got %d positions and %d holes", len(p.GeoLoop), len(p.Holes))
+	}
+	if got, _ := FromGeoPolygon(p); !reflect.DeepEqual(got, parse(t, polygon)) {
+		t.Errorf("polygon: got %v", got)
+	}
+
+	loop, err := ToGeoLoop(parse(t, polygon))
+	if err != nil || len(loop) != 3 {
+		t.Errorf("loop: got %v, %v", loop, err)
+	}
+}
@@ -0,0 +1,105 @@
+// Package codec moves GeoJSON between bloblang values and the raw
+// JSON the geometry libraries decode.
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrNoGeometry = errors.New("feature has no geometry")
+
+// Geometry returns the GeoJSON geometry in v as raw JSON. v may be a
+// decoded GeoJSON object, as bloblang holds it, or a JSON string or
+// byte slice. A Feature is unwrapped to its geometry.
+func Geometry(v any) ([]byte, error) {
+	raw, err := Raw(v)
+	if err != nil {
+		return nil, err
+	}
+
+	feature := struct {
+		Type     string          `json:"type"`
+		Geometry json.RawMessage `json:"geometry"`
+	}{}
+
+	err = json.Unmarshal(raw, &feature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid geojson: %w", err)
+	}
+
+	if feature.Type != "Feature" {
+		return raw, nil
+	}
+
+	if len(feature.Geometry) == 0 || string(feature.Geometry) == "null" {
+		return nil, ErrNoGeometry
+	}
+	return feature.Geometry, nil
+}
+
+// Raw returns v as raw JSON without looking at its contents
+func Raw(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), nil
+	case []byte:
+		return t, nil
+	}
+	return json.Marshal(v)
+}
+
+// Typed is a geometry knowing its GeoJSON type, as those of orb
+// and geoos do
+type Typed interface {
+	GeoJSONType() string
+}
+
+// Decode returns the GeoJSON geometry or feature in v decoded by
+// unmarshal, the geometry decoder of a library
+func Decode[G any](v any, unmarshal func([]byte) (G, error)) (G, error) {
+	raw, err := Geometry(v)
+	if err != nil {
+		var zero G
+		return zero, err
+	}
+	return unmarshal(raw)
+}
+
+// As returns the geometry in v decoded by decode as a T, failing
+// when it is another type of geometry
+func As[T Typed, G Typed](v any, decode func(any) (G, error)) (T, error) {
+	var zero T
+
+	g, err := decode(v)
+	if err != nil {
+		return zero, err
+	}
+
+	t, ok := any(g).(T)
+	if !ok {
+		return zero, fmt.Errorf("expected a GeoJSON %s, got %s", zero.GeoJSONType(), g.GeoJSONType())
+	}
+	return t, nil
+}
+
+// Value decodes raw JSON into a value bloblang can use
+func Value(raw []byte) (any, error) {
+	var v any
+
+	err := json.Unmarshal(raw, &v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Marshal encodes v as JSON and decodes it again as a bloblang value
+func Marshal(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Value(raw)
+}
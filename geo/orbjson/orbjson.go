@@ -0,0 +1,81 @@
+// Package orbjson converts between GeoJSON values and the geometry
+// types of github.com/paulmach/orb. It is the adapter package mod2blob
+// uses for orb based modules.
+package orbjson
+
+import (
+	"fmt"
+
+	"github.com/nibbleshift/mod2blob/geo/internal/codec"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// ToGeometry decodes a GeoJSON geometry or feature
+func ToGeometry(v any) (orb.Geometry, error) {
+	return codec.Decode(v, unmarshal)
+}
+
+func unmarshal(raw []byte) (orb.Geometry, error) {
+	g, err := geojson.UnmarshalGeometry(raw)
+	if err != nil {
+		return nil, err
+	}
+	return g.Geometry(), nil
+}
+
+// FromGeometry encodes g as a GeoJSON geometry. Rings and bounds are
+// emitted as polygons.
+func FromGeometry(g orb.Geometry) (any, error) {
+	if g == nil {
+		return nil, nil
+	}
+	return codec.Marshal(geojson.NewGeometry(g))
+}
+
+func to[T orb.Geometry](v any) (T, error) {
+	return codec.As[T](v, ToGeometry)
+}
+
+func ToPoint(v any) (orb.Point, error) { return to[orb.Point](v) }
+
+func ToMultiPoint(v any) (orb.MultiPoint, error) { return to[orb.MultiPoint](v) }
+
+func ToLineString(v any) (orb.LineString, error) { return to[orb.LineString](v) }
+
+func ToMultiLineString(v any) (orb.MultiLineString, error) { return to[orb.MultiLineString](v) }
+
+func ToPolygon(v any) (orb.Polygon, error) { return to[orb.Polygon](v) }
+
+func ToMultiPolygon(v any) (orb.MultiPolygon, error) { return to[orb.MultiPolygon](v) }
+
+func ToCollection(v any) (orb.Collection, error) { return to[orb.Collection](v) }
+
+// ToRing accepts a polygon without holes or a closed line string
+func ToRing(v any) (orb.Ring, error) {
+	g, err := ToGeometry(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := g.(type) {
+	case orb.Polygon:
+		if len(t) == 1 {
+			return t[0], nil
+		}
+	case orb.LineString:
+		if r := orb.Ring(t); r.Closed() {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a GeoJSON Polygon without holes, got %s", g.GeoJSONType())
+}
+
+// ToBound returns the bounding box of any GeoJSON geometry
+func ToBound(v any) (orb.Bound, error) {
+	g, err := ToGeometry(v)
+	if err != nil {
+		return orb.Bound{}, err
+	}
+	return g.Bound(), nil
+}
@@ -0,0 +1,67 @@
+package orbjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func parse(t *testing.T, s string) any {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		`{"type":"MultiPoint","coordinates":[[0,0],[1,1]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}`,
+	}
+
+	for _, input := range tests {
+		g, err := ToGeometry(parse(t, input))
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+
+		got, err := FromGeometry(g)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if want := parse(t, input); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestTo(t *testing.T) {
+	feature := `{"type":"Feature","properties":{},"geometry":{"type":"Point","coordinates":[1,2]}}`
+
+	p, err := ToPoint(feature)
+	if err != nil || p != (orb.Point{1, 2}) {
+		t.Errorf("point of a feature: got %v, %v", p, err)
+	}
+
+	if _, err := ToPolygon(`{"type":"Point","coordinates":[1,2]}`); err == nil || err.Error() != "expected a GeoJSON Polygon, got Point" {
+		t.Errorf("point as polygon: got %v", err)
+	}
+
+	r, err := ToRing(`{"type":"LineString","coordinates":[[0,0],[1,0],[1,1],[0,0]]}`)
+	if err != nil || len(r) != 4 {
+		t.Errorf("closed line string as ring: got %v, %v", r, err)
+	}
+
+	b, err := ToBound(`{"type":"LineString","coordinates":[[0,0],[2,1]]}`)
+	if err != nil || b != (orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{2, 1}}) {
+		t.Errorf("bound: got %v, %v", b, err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package spacejson converts between GeoJSON values and the geometry
+// types of github.com/spatial-go/geoos/space. It is the adapter package
+// mod2blob uses for geoos based modules.
+package spacejson
+
+import (
+	"fmt"
+
+	"github.com/nibbleshift/mod2blob/geo/internal/codec"
+	"github.com/spatial-go/geoos/geoencoding/geojson"
+	"github.com/spatial-go/geoos/space"
+)
+
+// ToGeometry decodes a GeoJSON geometry or feature
+func ToGeometry(v any) (space.Geometry, error) {
+	return codec.Decode(v, unmarshal)
+}
+
+func unmarshal(raw []byte) (space.Geometry, error) {
+	g, err := geojson.UnmarshalGeometry(raw)
+	if err != nil {
+		return nil, err
+	}
+	return g.Geometry(), nil
+}
+
+// FromGeometry encodes g as a GeoJSON geometry. Rings and bounds are
+// emitted as polygons.
+func FromGeometry(g space.Geometry) (any, error) {
+	if g == nil {
+		return nil, nil
+	}
+	return codec.Marshal(geojson.NewGeometry(g))
+}
+
+func to[T space.Geometry](v any) (T, error) {
+	return codec.As[T](v, ToGeometry)
+}
+
+func ToPoint(v any) (space.Point, error) { return to[space.Point](v) }
+
+func ToMultiPoint(v any) (space.MultiPoint, error) { return to[space.MultiPoint](v) }
+
+func ToLineString(v any) (space.LineString, error) { return to[space.LineString](v) }
+
+func ToMultiLineString(v any) (space.MultiLineString, error) { return to[space.MultiLineString](v) }
+
+func ToPolygon(v any) (space.Polygon, error) { return to[space.Polygon](v) }
+
+func ToMultiPolygon(v any) (space.MultiPolygon, error) { return to[space.MultiPolygon](v) }
+
+func ToCollection(v any) (space.Collection, error) { return to[space.Collection](v) }
+
+// ToRing accepts a polygon without holes or a closed line string
+func ToRing(v any) (space.Ring, error) {
+	g, err := ToGeometry(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := g.(type) {
+	case space.Polygon:
+		if len(t) == 1 {
+			return space.Ring(t[0]), nil
+		}
+	case space.LineString:
+		if t.IsClosed() {
+			return space.Ring(t), nil
+		}
+	}
+	return nil, fmt.Errorf("expected a GeoJSON Polygon without holes, got %s", g.GeoJSONType())
+}
+
+// ToBound returns the bounding box of any GeoJSON geometry
+func ToBound(v any) (space.Bound, error) {
+	g, err := ToGeometry(v)
+	if err != nil {
+		return space.Bound{}, err
+	}
+	return g.Bound(), nil
+}
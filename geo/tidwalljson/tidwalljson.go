@@ -0,0 +1,50 @@
+// Package tidwalljson converts between GeoJSON values and the objects
+// of github.com/tidwall/geojson, which go-geojson2h3 is built on.
+package tidwalljson
+
+import (
+	"fmt"
+
+	"github.com/nibbleshift/mod2blob/geo/internal/codec"
+	"github.com/tidwall/geojson"
+)
+
+// ToObject parses any GeoJSON object, including features and
+// feature collections
+func ToObject(v any) (geojson.Object, error) {
+	raw, err := codec.Raw(v)
+	if err != nil {
+		return nil, err
+	}
+	return geojson.Parse(string(raw), geojson.DefaultParseOptions)
+}
+
+// FromObject returns o as a GeoJSON value
+func FromObject(o geojson.Object) (any, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return codec.Value([]byte(o.JSON()))
+}
+
+// ToFeatureCollection parses a GeoJSON FeatureCollection
+func ToFeatureCollection(v any) (*geojson.FeatureCollection, error) {
+	o, err := ToObject(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fc, ok := o.(*geojson.FeatureCollection)
+	if !ok {
+		return nil, fmt.Errorf("expected a GeoJSON FeatureCollection, got %T", o)
+	}
+	return fc, nil
+}
+
+// FromFeatureCollection returns fc as a GeoJSON value
+func FromFeatureCollection(fc *geojson.FeatureCollection) (any, error) {
+	if fc == nil {
+		return nil, nil
+	}
+	return codec.Value([]byte(fc.JSON()))
+}
@@ -0,0 +1,73 @@
+package tidwalljson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func parse(t *testing.T, s string) any {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}}`,
+	}
+
+	for _, input := range tests {
+		o, err := ToObject(parse(t, input))
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+
+		got, err := FromObject(o)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if want := parse(t, input); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFeatureCollection(t *testing.T) {
+	input := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{}}]}`
+
+	fc, err := ToFeatureCollection(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fc.Children()); n != 1 {
+		t.Errorf("got %d features, want 1", n)
+	}
+
+	got, err := FromFeatureCollection(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parse(t, input); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := ToFeatureCollection(`{"type":"Point","coordinates":[1,2]}`); err == nil {
+		t.Error("a point: expected an error")
+	}
+}
+
+func TestNil(t *testing.T) {
+	if v, err := FromObject(nil); v != nil || err != nil {
+		t.Errorf("nil object: got %v, %v", v, err)
+	}
+	if v, err := FromFeatureCollection(nil); v != nil || err != nil {
+		t.Errorf("nil collection: got %v, %v", v, err)
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/nibbleshift/argenv v0.7.2
 	gotest.tools/v3 v3.5.1
 	mvdan.cc/gofumpt v0.6.0
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
@@ -39,5 +40,4 @@ require (
 	golang.org/x/text v0.15.0 // indirect
 	golang.org/x/tools v0.21.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
 )
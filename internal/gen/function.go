@@ -5,10 +5,31 @@ package bloblang
 
 import (
 	"{{getModulePath}}"
+	{{- range getImports }}
+	"{{ . }}"
+	{{- end }}
 	"github.com/benthosdev/benthos/v4/public/bloblang"
+	{{- if useRuntime }}
+	mod2blob "{{ runtimeImport }}"
+	{{- end }}
 )
 
+{{ enumTables }}
+
+{{- $register := registerName }}
+
 func init() {
+	mod2blobModules = append(mod2blobModules, {{ $register }})
+	{{- if getOptions.Init }}
+
+	if err := {{ $register }}(bloblang.GlobalEnvironment()); err != nil {
+		panic(err)
+	}
+	{{- end }}
+}
+
+// {{ $register }} registers the functions of {{ getModulePath }} with env
+func {{ $register }}(env *bloblang.Environment) error {
 	var (
 		err error
 	)
@@ -16,62 +37,78 @@ func init() {
 	{{- $nArgs := len .Args -}}
 	{{- if gt $nArgs 0 -}}
 	{{- $funcName := .Name }}
-	object{{.Name}}Spec := bloblang.NewPluginSpec().
-		{{- range $i, $el := .Args -}}
-			{{if $i}}.{{end}}Param(bloblang.New{{ benthosType .Type}}Param("{{$el.Name}}"))
+	{{- $f := . }}
+	{{- $variadic := isVariadic . }}
+	{{- if $variadic }}
+	object{{.Name}}Spec := bloblang.NewPluginSpec().Variadic()
+		{{- specMeta . }}
+	{{- else }}
+	object{{.Name}}Spec := bloblang.NewPluginSpec()
+		{{- range $el := params . }}.
+		Param(bloblang.New{{ benthosType .Type}}Param("{{ paramName $el }}"){{ with paramDesc $el }}.Description({{ printf "%q" . }}){{ end }}{{ if $el.Default }}.Default({{ $el.Default }}){{ else if isOptional $el }}.Optional(){{ end }})
 		{{- end }}
-	// {{.Description}}
-	err = bloblang.RegisterFunctionV2("{{ getPrefix }}{{ lower .Name}}", object{{.Name}}Spec,
+		{{- specMeta . }}
+	{{- end }}
+	{{- with memoize . }}
+	object{{ $f.Name }}Cache := mod2blob.NewCache("{{ pluginName $f }}", {{ . }})
+	{{- end }}
+	{{ docComment .Description }}
+	err = env.RegisterFunctionV2("{{ pluginName . }}", object{{.Name}}Spec,
 		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
 			{{- $argStr := "" -}}
-			{{- $returnVal := "" -}}
-			{{- range .Args -}}
-			{{- $bType := benthosType .Type }}
-			{{- $getType := $bType }}
-			{{- if eq $getType "Any" }}
-			{{ $getType = "" }}
+			{{- if $variadic }}
+			rawArgs, err := mod2blob.RawArgs(args.AsSlice(), {{ sub $nArgs 1 }})
+			if err != nil {
+				return nil, err
+			}
 			{{ end }}
-			{{.Name}}, err := args.Get{{ $getType }}("{{ .Name }}")
+
+			{{- range $i, $el := .Args }}
+			{{- if eq .Name $f.Dst }}
+			{{- else if not $variadic }}
+			{{.Name}}, err := args.{{ getter . }}("{{ paramName . }}")
 			if err != nil {
 				return nil, err
 			}
 
-			{{ .Name }}a := {{.Type}}({{.Name}})
+			{{ if eq .Name $f.Out -}}
+			{{ checkOut . }}
+			{{- else -}}
+			{{ convertArg . }}
+			{{- end }}
+			{{- else if hasPrefix "..." .Type }}
+			{{.Name}}a := rawArgs[{{ $i }}:]
+			{{- else }}
+			{{ convertRawArg . (printf "rawArgs[%d]" $i) }}
+			{{- end }}
 
 
 			{{- if eq $argStr "" -}}
-			{{ $argStr = (printf "%sa" .Name) }}
+			{{ $argStr = passArg $f . }}
 			{{ else }}
-			{{ $argStr = (printf "%s, %sa" $argStr .Name) }}
+			{{ $argStr = (printf "%s, %s" $argStr (passArg $f .)) }}
 			{{- end -}}
 			{{ end -}}
 
-			{{- range .Return -}}
-			{{- if eq $returnVal "" -}}
-			{{ $returnVal = (printf "%s" .Name) }}
-			{{ else }}
-			{{ $returnVal = (printf "%s, %s" $returnVal .Name) }}
-			{{- end -}}
-			{{- end -}}
+			{{- $qualName := printf "%s.%s" getModuleName $funcName }}
+			{{- $call := printf "%s(%s)" $qualName $argStr }}
 
-			return func() (any, error) {
-				{{ $nReturn := len .Return }}
-				{{ if gt $nReturn 1 }}
-					{{$returnVal}} := {{getModuleName}}.{{$funcName}}({{ $argStr }})
-					obj := map[string]any{}
-					{{- range .Return }}
-					obj["{{.Name}}"] = {{.Name}}
-					{{- end }}
-					return obj, nil
+			return {{ if fold . }}mod2blob.Fold({{ end }}{{ if memoize . }}mod2blob.Memoize(object{{ .Name }}Cache, args.AsSlice(), {{ end }}{{ with guard . }}mod2blob.Guard("{{ pluginName $f }}", {{ . }}, args.AsSlice(), {{ end }}{{ if getOptions.Recover }}mod2blob.Recover("{{ pluginName . }}", args.AsSlice(), {{ end }}func() (any, error) {
+				{{- with prepareOut . }}
+				{{ . }}
+				{{ end }}
+				{{- if .Out }}
+				{{ returnOut $qualName . $call }}
 				{{ else }}
-				return {{getModuleName}}.{{$funcName}}({{ $argStr }}), nil
-				{{- end -}}
-			}, nil
+				{{ returnResults $qualName . $call }}
+				{{ end -}}
+			}{{ if getOptions.Recover }}){{ end }}{{ if guard . }}){{ end }}{{ if memoize . }}){{ end }}{{ if fold . }}){{ end }}, nil
 	})
 
 	if err != nil {
-		panic(err)
+		return err
 	}
 	{{ end }}
 {{- end }}
+	return nil
 }`
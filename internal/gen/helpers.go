@@ -0,0 +1,27 @@
+package gen
+
+// Helpers is written once per output directory and holds the
+// registry of every module generated into it. The conversions the
+// generated code calls live in github.com/nibbleshift/mod2blob/runtime.
+var Helpers string = `
+package bloblang
+
+import (
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// mod2blobModules holds the Register function of every module
+// generated into this package
+var mod2blobModules []func(env *bloblang.Environment) error
+
+// Register registers the functions of every module generated into
+// this package with env, stopping at the first that fails.
+func Register(env *bloblang.Environment) error {
+	for _, register := range mod2blobModules {
+		if err := register(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`
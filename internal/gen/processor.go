@@ -7,7 +7,7 @@ processor_resources:
       root = {}
       {{- range . }}
       {{ $argStr := "" }}
-      {{- range .Args }}
+      {{- range params . }}
       {{- $randValue := randInt 1 1000 -}}
       {{- if eq $argStr "" -}}
       {{- $argStr = (printf "%d"  $randValue) -}}
@@ -15,5 +15,32 @@ processor_resources:
       {{ $argStr = (printf "%s, %d" $argStr $randValue) }}
       {{- end -}}
       {{- end -}}
-      root.{{lower .Name}} = {{lower .Name}}({{$argStr}})
+      root.{{ pluginName . }} = {{ pluginName . }}({{$argStr}})
       {{- end }}`
+
+// Examples runs the examples of the generated functions as benthos
+// unit tests, kept apart from Processor so they don't depend on its
+// mapping
+var Examples string = `---
+processor_resources:
+{{- range $f := . }}
+{{- range $i, $ex := .Examples }}
+  - label: example_{{ pluginName $f }}_{{ $i }}
+    mapping: {{ toJson $ex.Mapping }}
+{{- end }}
+{{- end }}
+
+tests:
+{{- range $f := . }}
+{{- range $i, $ex := .Examples }}
+{{- range $j, $r := $ex.Results }}
+  - name: {{ toJson (printf "%s example %d.%d" (pluginName $f) $i $j) }}
+    target_processors: example_{{ pluginName $f }}_{{ $i }}
+    input_batch:
+      - content: {{ toJson (index $r 0) }}
+    output_batches:
+      - - content_equals: {{ toJson (index $r 1) }}
+{{- end }}
+{{- end }}
+{{- end }}
+`
@@ -0,0 +1,58 @@
+package module
+
+// builtinAdapters cover stdlib value types that block large parts of
+// net, net/url, math/big and regexp, and the geometry types of the geo
+// modules. The stdlib conversions live in the runtime package, which
+// the generated code imports whenever it calls into it; the geo ones
+// live in the geo module. Adapters from the project config take
+// precedence.
+var builtinAdapters = []Adapter{
+	{Type: "net.IP", Param: "String", ToGo: "mod2blob.ParseIP", FromGo: "mod2blob.FormatIP"},
+	{Type: "netip.Addr", Param: "String", ToGo: "mod2blob.ParseAddr", FromGo: "mod2blob.FormatAddr"},
+	{Type: "netip.Prefix", Param: "String", ToGo: "mod2blob.ParsePrefix", FromGo: "mod2blob.FormatPrefix"},
+	{Type: "*url.URL", Param: "String", ToGo: "mod2blob.ParseURL", FromGo: "mod2blob.FormatURL"},
+	{Type: "*big.Int", Param: "Any", ToGo: "mod2blob.ParseBigInt", FromGo: "mod2blob.FormatBigInt"},
+	{Type: "*big.Float", Param: "Any", ToGo: "mod2blob.ParseBigFloat", FromGo: "mod2blob.FormatBigFloat"},
+	{Type: "*big.Rat", Param: "Any", ToGo: "mod2blob.ParseBigRat", FromGo: "mod2blob.FormatBigRat"},
+	{Type: "[16]byte", Param: "String", ToGo: "mod2blob.ParseUUID", FromGo: "mod2blob.FormatUUID"},
+	{Type: "uuid.UUID", Param: "String", ToGo: "mod2blob.ParseUUID", FromGo: "mod2blob.FormatUUID"},
+	{Type: "*regexp.Regexp", Param: "String", ToGo: "mod2blob.ParseRegexp", FromGo: "mod2blob.FormatRegexp"},
+	{Type: "*time.Location", Param: "String", ToGo: "mod2blob.ParseLocation", FromGo: "mod2blob.FormatLocation"},
+
+	// geometries are exchanged as GeoJSON
+	{Type: "orb.Geometry", Import: orbImport, Param: "Any", ToGo: "orbjson.ToGeometry", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.Point", Import: orbImport, Param: "Any", ToGo: "orbjson.ToPoint", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.MultiPoint", Import: orbImport, Param: "Any", ToGo: "orbjson.ToMultiPoint", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.LineString", Import: orbImport, Param: "Any", ToGo: "orbjson.ToLineString", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.MultiLineString", Import: orbImport, Param: "Any", ToGo: "orbjson.ToMultiLineString", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.Ring", Import: orbImport, Param: "Any", ToGo: "orbjson.ToRing", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.Polygon", Import: orbImport, Param: "Any", ToGo: "orbjson.ToPolygon", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.MultiPolygon", Import: orbImport, Param: "Any", ToGo: "orbjson.ToMultiPolygon", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.Collection", Import: orbImport, Param: "Any", ToGo: "orbjson.ToCollection", FromGo: "orbjson.FromGeometry"},
+	{Type: "orb.Bound", Import: orbImport, Param: "Any", ToGo: "orbjson.ToBound", FromGo: "orbjson.FromGeometry"},
+	{Type: "space.Geometry", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToGeometry", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.Point", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToPoint", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.MultiPoint", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToMultiPoint", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.LineString", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToLineString", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.MultiLineString", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToMultiLineString", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.Ring", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToRing", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.Polygon", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToPolygon", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.MultiPolygon", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToMultiPolygon", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.Collection", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToCollection", FromGo: "spacejson.FromGeometry"},
+	{Type: "space.Bound", Import: spaceImport, Param: "Any", ToGo: "spacejson.ToBound", FromGo: "spacejson.FromGeometry"},
+	{Type: "h3.Cell", Import: h3Import, Param: "Any", ToGo: "h3json.ToCell", FromGo: "h3json.FromCell"},
+	{Type: "[]h3.Cell", Import: h3Import, Param: "Any", ToGo: "h3json.ToCells", FromGo: "h3json.FromCells"},
+	{Type: "h3.LatLng", Import: h3Import, Param: "Any", ToGo: "h3json.ToLatLng", FromGo: "h3json.FromLatLng"},
+	{Type: "h3.GeoLoop", Import: h3Import, Param: "Any", ToGo: "h3json.ToGeoLoop", FromGo: "h3json.FromGeoLoop"},
+	{Type: "h3.GeoPolygon", Import: h3Import, Param: "Any", ToGo: "h3json.ToGeoPolygon", FromGo: "h3json.FromGeoPolygon"},
+	{Type: "h3.CellBoundary", Import: h3Import, FromGo: "h3json.FromCellBoundary"},
+	{Type: "geojson.Object", Import: tidwallImport, Param: "Any", ToGo: "tidwalljson.ToObject", FromGo: "tidwalljson.FromObject"},
+	{Type: "*geojson.FeatureCollection", Import: tidwallImport, Param: "Any", ToGo: "tidwalljson.ToFeatureCollection", FromGo: "tidwalljson.FromFeatureCollection"},
+}
+
+const (
+	orbImport     = "github.com/nibbleshift/mod2blob/geo/orbjson"
+	spaceImport   = "github.com/nibbleshift/mod2blob/geo/spacejson"
+	h3Import      = "github.com/nibbleshift/mod2blob/geo/h3json"
+	tidwallImport = "github.com/nibbleshift/mod2blob/geo/tidwalljson"
+)
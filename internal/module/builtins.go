@@ -0,0 +1,106 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+)
+
+// builtinFunctions are the bloblang functions of a benthos build with
+// the pure and io components, as listed by
+// benthos list --format json bloblang-functions
+//
+// Methods aren't listed, as generated plugins can't clash with them:
+// mod2blob only registers functions, which bloblang keeps apart from
+// methods, calling foo() a function and this.foo() a method. A function
+// named like the uppercase method leaves this.uppercase() as it is.
+var builtinFunctions = []string{
+	"batch_index", "batch_size", "content", "count", "counter", "deleted", "env", "error", "errored",
+	"file", "file_rel", "hostname", "json", "ksuid", "meta", "metadata", "nanoid", "nothing", "now",
+	"random_int", "range", "root_meta", "throw", "timestamp_unix", "timestamp_unix_micro",
+	"timestamp_unix_milli", "timestamp_unix_nano", "tracing_id", "tracing_span", "uuid_v4", "var",
+}
+
+// LoadBuiltins reads the bloblang functions of a benthos build from
+// the output of benthos list --format json bloblang-functions
+func LoadBuiltins(dumpPath string) ([]string, error) {
+	raw, err := os.ReadFile(dumpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := map[string][]string{}
+
+	err = json.Unmarshal(raw, &dump)
+	if err != nil {
+		return nil, err
+	}
+
+	functions, ok := dump["bloblang-functions"]
+	if !ok {
+		log.Println(dumpPath + ": no bloblang-functions in the list")
+		return nil, ErrInvalidConfig
+	}
+	return functions, nil
+}
+
+// builtins returns the names of the bloblang built-in functions
+func (mod *Module) builtins() []string {
+	if mod.Options.Builtins != nil {
+		return mod.Options.Builtins
+	}
+	return builtinFunctions
+}
+
+// resolveBuiltins deals with the generated functions whose names are
+// taken by a bloblang built-in, which they would replace, according
+// to Options.BuiltinClash: they are left out, registered with the
+// module in front of their name, or registered under the alias the
+// project config gives them and otherwise left out
+func (mod *Module) resolveBuiltins() {
+	builtins := mod.builtins()
+
+	funcs := []*Function{}
+	for _, f := range mod.Map["function"] {
+		name := mod.pluginName(*f)
+		if !slices.Contains(builtins, name) {
+			funcs = append(funcs, f)
+			continue
+		}
+
+		switch alias := mod.builtinAlias(f); {
+		case mod.Options.BuiltinClash == BuiltinClashPrefix:
+			f.BloblangName = mod.Options.Prefix + mod.qualifiedName(*f)
+		case mod.Options.BuiltinClash == BuiltinClashAlias && alias != "":
+			f.BloblangName = alias
+		default:
+			mod.skip(f, fmt.Sprintf("its name %q is a bloblang built-in", name))
+			continue
+		}
+
+		log.Printf("%s: Renamed function %s to %q, %q is a bloblang built-in\n", mod.GetName(), f.Name, f.BloblangName, name)
+		funcs = append(funcs, f)
+	}
+	mod.Map["function"] = funcs
+}
+
+// qualifiedName returns the name of f with the module in front of
+// it, which NamingModule names already have
+func (mod *Module) qualifiedName(f Function) string {
+	name := mod.baseName(f)
+	if mod.Options.Naming == NamingModule {
+		return name
+	}
+	return mod.qualifier() + "_" + name
+}
+
+// builtinAlias returns the name the project config gives f for when
+// its own is taken by a built-in
+func (mod *Module) builtinAlias(f *Function) string {
+	if fc := mod.functionConfig(f.Name); fc != nil {
+		return fc.Alias
+	}
+	return ""
+}
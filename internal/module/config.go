@@ -0,0 +1,304 @@
+package module
+
+import (
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the project configuration read from the file given
+// with -config
+type Config struct {
+	Adapters []Adapter `json:"adapters"`
+	// Functions holds settings for single functions, keyed by the
+	// qualified name such as math.Frexp, or by the bare name
+	Functions map[string]FunctionConfig `json:"functions"`
+	// Include, when set, keeps only the functions matching one of its
+	// filters, and Exclude leaves out those matching one of its own
+	Include []Filter `json:"include"`
+	Exclude []Filter `json:"exclude"`
+
+	include []*filterMatcher
+	exclude []*filterMatcher
+	// path is the file the config was read from
+	path string
+}
+
+// FunctionConfig overrides the options for a single function
+type FunctionConfig struct {
+	// Skip leaves the function out
+	Skip bool `json:"skip"`
+	// Name is the bloblang name of the function, replacing the one
+	// the naming strategy gives it
+	Name string `json:"name"`
+	// Description replaces the doc comment of the function in its
+	// plugin spec
+	Description string `json:"description"`
+	// Params renames params, keyed by their Go name
+	Params map[string]string `json:"params"`
+	// Results is the shape of multiple results, see Options.Results
+	Results string `json:"results"`
+	// Alias is the name the function is registered under when its
+	// own is taken by a bloblang built-in, see Options.BuiltinClash
+	Alias string `json:"alias"`
+	// Defaults makes trailing params optional, keyed by their Go or
+	// bloblang name
+	Defaults map[string]any `json:"defaults"`
+	// Examples document the function in its plugin spec
+	Examples []Example `json:"examples"`
+	// Version is the version of the plugin that added the function,
+	// shown in its plugin spec
+	Version string `json:"version"`
+	// Effects replaces the side-effect class mod2blob finds for the
+	// function, one of pure, impure or dangerous
+	Effects string `json:"effects"`
+	// MaxStringBytes, MaxArrayElements and Timeout replace the
+	// limits of Options for the function, Timeout as a duration such
+	// as 500ms
+	MaxStringBytes   int    `json:"max_string_bytes"`
+	MaxArrayElements int    `json:"max_array_elements"`
+	Timeout          string `json:"timeout"`
+	// Memoize replaces Options.Memoize for the function, which is
+	// only cached when it is pure
+	Memoize int `json:"memoize"`
+}
+
+// Example is a mapping using a function, shown in the docs of its
+// plugin and run by benthos against Results when docs are tested
+type Example struct {
+	Summary string `json:"summary"`
+	Mapping string `json:"mapping"`
+	// Results pairs input documents with the output of Mapping
+	Results [][2]string `json:"results"`
+}
+
+// Adapter teaches mod2blob how to pass a Go type it doesn't know
+// about. ToGo has the signature func(v P) (Type, error), where P is
+// the Go type of Param (any, string, int64, float64 or bool), and
+// FromGo has the signature func(v Type) (any, error). Either may be
+// left empty if the type only appears as a param or as a result.
+type Adapter struct {
+	// Type is the Go type as written in signatures, qualified with
+	// its package name, e.g. *mat.Dense
+	Type string `json:"type"`
+	// Import is the path of the package holding ToGo and FromGo
+	Import string `json:"import"`
+	// Param is the bloblang param kind the type is accepted as
+	Param string `json:"param"`
+	// ToGo is the qualified name of the param conversion function
+	ToGo string `json:"to_go"`
+	// FromGo is the qualified name of the result conversion function
+	FromGo string `json:"from_go"`
+}
+
+var paramKinds = map[string]string{
+	"Any":     "any",
+	"String":  "string",
+	"Int64":   "int64",
+	"Float64": "float64",
+	"Bool":    "bool",
+}
+
+// LoadConfig reads and validates the project config at configPath
+func LoadConfig(configPath string) (*Config, error) {
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{path: configPath}
+
+	err = yaml.UnmarshalStrict(raw, config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	for i := range c.Adapters {
+		a := &c.Adapters[i]
+
+		if a.Param == "" {
+			a.Param = "Any"
+		}
+
+		if a.Type == "" || (a.ToGo == "" && a.FromGo == "") {
+			log.Printf("adapter %d: type and at least one of to_go or from_go are required\n", i)
+			return ErrInvalidConfig
+		}
+
+		if _, ok := paramKinds[a.Param]; !ok {
+			log.Printf("adapter %s: unknown param kind %q\n", a.Type, a.Param)
+			return ErrInvalidConfig
+		}
+	}
+
+	if err := c.compileFilters(); err != nil {
+		return err
+	}
+
+	for name, fc := range c.Functions {
+		switch fc.Results {
+		case "", ResultsArray, ResultsNamed, ResultsIndexed:
+		default:
+			log.Printf("function %s: results must be one of array, named or indexed, got %q\n", name, fc.Results)
+			return ErrInvalidConfig
+		}
+
+		switch fc.Effects {
+		case "", EffectsPure, EffectsImpure, EffectsDangerous:
+		default:
+			log.Printf("function %s: effects must be one of pure, impure or dangerous, got %q\n", name, fc.Effects)
+			return ErrInvalidConfig
+		}
+
+		if fc.MaxStringBytes < 0 || fc.MaxArrayElements < 0 || fc.Memoize < 0 {
+			log.Printf("function %s: max_string_bytes, max_array_elements and memoize must not be negative\n", name)
+			return ErrInvalidConfig
+		}
+
+		if fc.Timeout != "" {
+			if d, err := time.ParseDuration(fc.Timeout); err != nil || d <= 0 {
+				log.Printf("function %s: timeout must be a positive duration such as 500ms, got %q\n", name, fc.Timeout)
+				return ErrInvalidConfig
+			}
+		}
+
+		names := []string{}
+		for _, n := range []string{fc.Name, fc.Alias} {
+			if n != "" {
+				names = append(names, n)
+			}
+		}
+		for _, n := range fc.Params {
+			names = append(names, n)
+		}
+
+		for _, n := range names {
+			if !pluginNamePattern.MatchString(n) {
+				log.Printf("function %s: %q is not a valid bloblang name, use snake_case\n", name, n)
+				return ErrInvalidConfig
+			}
+		}
+	}
+	return nil
+}
+
+// functionConfig returns the project config for funcName, or nil
+func (mod *Module) functionConfig(funcName string) *FunctionConfig {
+	if mod.Options.Config == nil {
+		return nil
+	}
+
+	for _, key := range []string{mod.Name + "." + funcName, funcName} {
+		if fc, ok := mod.Options.Config.Functions[key]; ok {
+			return &fc
+		}
+	}
+	return nil
+}
+
+// applyOverrides applies the name, description and param names the
+// project config gives f, and reports whether the config skips it
+func (mod *Module) applyOverrides(f *Function) (bool, error) {
+	fc := mod.functionConfig(f.Name)
+	if fc == nil {
+		return false, nil
+	}
+
+	if fc.Skip {
+		return true, nil
+	}
+
+	if fc.Name != "" {
+		f.BloblangName = fc.Name
+	}
+
+	if fc.Description != "" {
+		f.Description = fc.Description
+	}
+
+	for goName, param := range fc.Params {
+		i := slices.IndexFunc(f.Args, func(a Arg) bool { return a.Name == goName })
+		if i < 0 {
+			log.Printf("function %s: no param %s to rename, it takes %v\n", f.Name, goName, f.Args)
+			return false, ErrInvalidConfig
+		}
+		f.Args[i].Param = param
+	}
+	return false, nil
+}
+
+// adapterFor returns the adapter registered for typeStr, or nil.
+// Types declared by the module itself appear unqualified in its
+// signatures, so they are also matched by their qualified name.
+func (mod *Module) adapterFor(typeStr string) *Adapter {
+	qualified := mod.qualifyType(typeStr)
+
+	if mod.Options.Config != nil {
+		for i, a := range mod.Options.Config.Adapters {
+			if a.Type == typeStr || a.Type == qualified {
+				return &mod.Options.Config.Adapters[i]
+			}
+		}
+	}
+
+	for i, a := range builtinAdapters {
+		if a.Type == typeStr || a.Type == qualified {
+			return &builtinAdapters[i]
+		}
+	}
+	return nil
+}
+
+// qualifyType prefixes an exported type local to the module with the
+// module name, so *Dense becomes *mat.Dense
+func (mod *Module) qualifyType(typeStr string) string {
+	base := strings.TrimLeft(typeStr, "*[]")
+	if base == "" || strings.Contains(base, ".") || !unicode.IsUpper([]rune(base)[0]) {
+		return typeStr
+	}
+
+	return typeStr[:len(typeStr)-len(base)] + mod.Name + "." + base
+}
+
+// getImports returns the adapter and enum packages the generated
+// functions refer to
+func (mod *Module) getImports() []string {
+	imports := []string{}
+
+	add := func(a *Adapter) {
+		if a != nil && a.Import != "" && a.Import != mod.Path && !slices.Contains(imports, a.Import) {
+			imports = append(imports, a.Import)
+		}
+	}
+
+	for _, f := range mod.Map["function"] {
+		for _, arg := range f.Args {
+			add(mod.adapterFor(arg.Type))
+		}
+		for _, ret := range f.Return {
+			add(mod.adapterFor(ret.Type))
+		}
+	}
+
+	for _, e := range mod.usedEnums() {
+		add(&Adapter{Import: e.Import})
+	}
+
+	slices.Sort(imports)
+
+	return imports
+}
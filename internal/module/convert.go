@@ -0,0 +1,261 @@
+package module
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// passthrough are the param types handed to the module exactly as
+// bloblang provides them
+var passthrough = []string{"any", "interface{}"}
+
+// checkedConversion assigns the result of a helper call that can fail
+// to <name>a and bails out of the constructor if it does
+func checkedConversion(name string, call string) string {
+	return fmt.Sprintf("%sa, err := %s\nif err != nil {\nreturn nil, err\n}", name, call)
+}
+
+// convertArg returns the statements that turn the value fetched from
+// the bloblang params into <name>a, the value handed to the module.
+// Integer conversions are range checked rather than wrapped.
+func convertArg(arg Arg) string {
+	name := arg.Name
+
+	switch arg.Type {
+	case "float64", "int64", "string", "bool":
+		return fmt.Sprintf("%sa := %s", name, name)
+	case "float32":
+		return checkedConversion(name, fmt.Sprintf("mod2blob.Float32(%q, %s)", name, name))
+	case "int", "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return checkedConversion(name, fmt.Sprintf("mod2blob.Int[%s](%q, %s)", arg.Type, name, name))
+	}
+
+	// everything else is fetched with Get
+	return convertRawArg(arg, name)
+}
+
+// convertArg uses the adapter registered for arg's type, falling
+// back to the built-in conversions
+func (mod *Module) convertArg(arg Arg) string {
+	if e := mod.enumFor(arg.Type); e != nil {
+		return checkedConversion(arg.Name, mod.enumConversion(e, arg.Name, arg.Name))
+	}
+
+	if a := mod.adapterFor(arg.Type); a != nil {
+		return checkedConversion(arg.Name, fmt.Sprintf("%s(%s)", a.ToGo, arg.Name))
+	}
+	return convertArg(arg)
+}
+
+// convertRawArg uses the adapter registered for arg's type, falling
+// back to the built-in conversions
+func (mod *Module) convertRawArg(arg Arg, expr string) string {
+	if e := mod.enumFor(arg.Type); e != nil {
+		return checkedConversion(arg.Name, mod.enumConversion(e, arg.Name, expr))
+	}
+
+	a := mod.adapterFor(arg.Type)
+	if a == nil {
+		return convertRawArg(arg, expr)
+	}
+
+	if a.Param != "Any" {
+		// the raw value has to be brought to the adapter's param kind first
+		fetch := fmt.Sprintf("%sv, err := %s(%q, %s)\nif err != nil {\nreturn nil, err\n}\n",
+			arg.Name, rawConverter(paramKinds[a.Param]), arg.Name, expr)
+		return fetch + checkedConversion(arg.Name, fmt.Sprintf("%s(%sv)", a.ToGo, arg.Name))
+	}
+
+	return checkedConversion(arg.Name, fmt.Sprintf("%s(%s)", a.ToGo, expr))
+}
+
+// convertRawArg is convertArg for a value of type any, such as the
+// fixed params of a variadic function taken from ParsedParams.AsSlice
+func convertRawArg(arg Arg, expr string) string {
+	name := arg.Name
+
+	if slices.Contains(passthrough, arg.Type) {
+		return fmt.Sprintf("%sa := %s", name, expr)
+	}
+
+	if elem, ok := pointerElem(arg.Type); ok {
+		return checkedConversion(name, fmt.Sprintf("mod2blob.Optional(%q, %s, %s)", name, expr, rawConverter(elem)))
+	}
+
+	if conv := rawConverter(arg.Type); conv != "" {
+		return checkedConversion(name, fmt.Sprintf("%s(%q, %s)", conv, name, expr))
+	}
+
+	return fmt.Sprintf("%sa := %s(%s)", name, arg.Type, expr)
+}
+
+// rawConverter names the helper that converts a raw bloblang value
+// to typeStr, or returns an empty string if there isn't one
+func rawConverter(typeStr string) string {
+	switch typeStr {
+	case "float32", "float64":
+		return fmt.Sprintf("mod2blob.AnyFloat[%s]", typeStr)
+	case "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32":
+		return fmt.Sprintf("mod2blob.AnyInt[%s]", typeStr)
+	case "byte", "rune":
+		return fmt.Sprintf("mod2blob.Char[%s]", typeStr)
+	case "uint", "uint64":
+		return fmt.Sprintf("mod2blob.Uint[%s]", typeStr)
+	case "string":
+		return "mod2blob.AnyString"
+	case "bool":
+		return "mod2blob.AnyBool"
+	case "[]byte":
+		return "mod2blob.Bytes"
+	case "[]bool":
+		return "mod2blob.Bools"
+	case "[]uint", "[]uint64":
+		return fmt.Sprintf("mod2blob.Uints[%s]", elemType(typeStr))
+	case "[]int", "[]int8", "[]int16", "[]int32", "[]int64", "[]uint8", "[]uint16", "[]uint32", "[]rune":
+		return fmt.Sprintf("mod2blob.Ints[%s]", elemType(typeStr))
+	case "[]float32", "[]float64":
+		return fmt.Sprintf("mod2blob.Floats[%s]", elemType(typeStr))
+	}
+	return ""
+}
+
+// convertResult returns an expression of type (any, error) that applies
+// the NaN/Inf and big uint policies to expr and normalises anything
+// bloblang can't use directly, or an empty string when expr can be
+// returned as is
+func (mod *Module) convertResult(funcName string, ret Arg, expr string) string {
+	if a := mod.adapterFor(ret.Type); a != nil && a.FromGo != "" {
+		return fmt.Sprintf("%s(%s)", a.FromGo, expr)
+	}
+
+	if conv := mod.enumResult(funcName, ret.Type, expr); conv != "" {
+		return conv
+	}
+
+	if elem, ok := pointerElem(ret.Type); ok {
+		conv := mod.convertResult(funcName, Arg{Type: elem}, "v")
+		if conv == "" {
+			return fmt.Sprintf("mod2blob.Deref(%s)", expr)
+		}
+		return fmt.Sprintf("mod2blob.DerefFunc(%s, func(v %s) (any, error) {\nreturn %s\n})", expr, elem, conv)
+	}
+
+	if conv := mod.collectResult(funcName, ret, expr); conv != "" {
+		return conv
+	}
+
+	switch ret.Type {
+	case "float64":
+		return fmt.Sprintf("mod2blob.Float(%q, %s, %q)", funcName, expr, mod.Options.NonFinite)
+	case "float32":
+		return fmt.Sprintf("mod2blob.Float(%q, float64(%s), %q)", funcName, expr, mod.Options.NonFinite)
+	case "uint", "uint64":
+		if mod.Options.BigUint == BigUintString {
+			return fmt.Sprintf("mod2blob.BigUint(%s)", expr)
+		}
+		return ""
+	case "[]byte", "error":
+		return ""
+	}
+
+	if isScalar(ret.Type) {
+		return ""
+	}
+
+	return fmt.Sprintf("mod2blob.Normalise(%q, %s, %q, %q)", funcName, expr, mod.Options.NonFinite, mod.Options.BigUint)
+}
+
+// collectResult returns the expression collecting an iterator or
+// channel result into an array, or an empty string for other types
+func (mod *Module) collectResult(funcName string, ret Arg, expr string) string {
+	switch {
+	case strings.HasPrefix(ret.Type, "iter.Seq["):
+		return fmt.Sprintf("mod2blob.Seq(%q, %s, %d, %q, %q)",
+			funcName, expr, mod.Options.MaxElements, mod.Options.NonFinite, mod.Options.BigUint)
+	case strings.HasPrefix(ret.Type, "iter.Seq2["):
+		return fmt.Sprintf("mod2blob.Seq2(%q, %s, %d, %q, %q, %q)",
+			funcName, expr, mod.Options.MaxElements, mod.Options.IterPairs, mod.Options.NonFinite, mod.Options.BigUint)
+	case strings.HasPrefix(ret.Type, "chan "), strings.HasPrefix(ret.Type, "<-chan "):
+		return fmt.Sprintf("mod2blob.Chan(%q, %s, %d, %d, %q, %q)",
+			funcName, expr, mod.Options.MaxElements, mod.Options.ChanWait, mod.Options.NonFinite, mod.Options.BigUint)
+	}
+	return ""
+}
+
+// needsConversion reports whether any of the results is rewritten
+// by convertResult
+func (mod *Module) needsConversion(results []Arg) bool {
+	for _, r := range results {
+		if mod.convertResult("", r, r.Name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptional reports whether arg may be omitted from the call
+func isOptional(arg Arg) bool {
+	_, ok := pointerElem(arg.Type)
+	return ok
+}
+
+// isVariadic reports whether the last param of f is ...any, in which
+// case the plugin takes its arguments positionally
+func isVariadic(f Function) bool {
+	if len(f.Args) == 0 {
+		return false
+	}
+
+	elem, ok := strings.CutPrefix(f.Args[len(f.Args)-1].Type, "...")
+	return ok && slices.Contains(passthrough, elem)
+}
+
+// callArg returns how arg is passed to the module
+func callArg(arg Arg) string {
+	if strings.HasPrefix(arg.Type, "...") {
+		return arg.Name + "a..."
+	}
+	return arg.Name + "a"
+}
+
+// paramName returns the bloblang name of the param for arg. Bloblang
+// only accepts snake_case names, so unless the project config names
+// it bitSize becomes bit_size and srcURL src_url.
+func paramName(arg Arg) string {
+	if arg.Param != "" {
+		return arg.Param
+	}
+	return snakeCase(arg.Name)
+}
+
+// paramType returns the bloblang param kind for typeStr, preferring
+// a registered adapter over toBenthosType
+func (mod *Module) paramType(typeStr string) string {
+	if a := mod.adapterFor(typeStr); a != nil {
+		return a.Param
+	}
+	if mod.enumFor(typeStr) != nil {
+		// names or numbers
+		return "Any"
+	}
+	return toBenthosType(typeStr)
+}
+
+// getter returns the ParsedParams method used to fetch arg
+func (mod *Module) getter(arg Arg) string {
+	bType := mod.paramType(arg.Type)
+	if bType == "Any" {
+		return "Get"
+	}
+	return "Get" + bType
+}
+
+// isScalar reports whether typeStr is a native non-slice type
+func isScalar(typeStr string) bool {
+	return !strings.HasPrefix(typeStr, "[]") && slices.Contains(native, typeStr)
+}
+
+func elemType(typeStr string) string {
+	return strings.TrimPrefix(typeStr, "[]")
+}
@@ -0,0 +1,149 @@
+package module
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// setDefaults makes the params of f given a default in the project
+// config optional, with Arg.Default holding the value as a Go literal
+// of the param kind. Params are matched by their Go or bloblang name
+// and only trailing params may have a default, so that positional
+// calls can leave them out.
+func (mod *Module) setDefaults(f *Function) error {
+	fc := mod.functionConfig(f.Name)
+	if fc == nil || len(fc.Defaults) == 0 {
+		return nil
+	}
+
+	if isVariadic(*f) {
+		log.Printf("function %s: defaults are not supported for variadic functions\n", f.Name)
+		return ErrInvalidConfig
+	}
+
+	found := 0
+	for i := range f.Args {
+		arg := &f.Args[i]
+
+		v, ok := fc.Defaults[arg.Name]
+		if !ok {
+			v, ok = fc.Defaults[paramName(*arg)]
+		}
+		if !ok || arg.Name == f.Dst {
+			continue
+		}
+
+		lit, err := mod.defaultLiteral(*arg, v)
+		if err != nil {
+			log.Printf("function %s: default of %s: %s\n", f.Name, arg.Name, err)
+			return ErrInvalidConfig
+		}
+		arg.Default = lit
+		found++
+	}
+
+	if found != len(fc.Defaults) {
+		log.Printf("function %s: defaults given for unknown params, it takes %v\n", f.Name, f.Args)
+		return ErrInvalidConfig
+	}
+
+	optional := false
+	for _, arg := range params(*f) {
+		if optional && arg.Default == "" {
+			log.Printf("function %s: %s follows a param with a default, only trailing params may have one\n", f.Name, arg.Name)
+			return ErrInvalidConfig
+		}
+		optional = arg.Default != ""
+	}
+	return nil
+}
+
+// defaultLiteral returns v, as decoded from the config, as a Go
+// literal of the type bloblang hands the plugin for arg. A byte or
+// rune default may be given as a single character, as the param is.
+func (mod *Module) defaultLiteral(arg Arg, v any) (string, error) {
+	if arg.Type == "byte" || arg.Type == "rune" {
+		switch v := v.(type) {
+		case string:
+			if utf8.RuneCountInString(v) != 1 || (arg.Type == "byte" && len(v) != 1) {
+				return "", fmt.Errorf("expected a single character, got %q", v)
+			}
+			return strconv.Quote(v), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return fmt.Sprintf("int64(%d)", int64(v)), nil
+			}
+		}
+		return "", fmt.Errorf("expected a character or an integer, got %v", v)
+	}
+
+	switch mod.paramType(arg.Type) {
+	case "Int64":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return "", fmt.Errorf("expected an integer, got %v", v)
+		}
+		return fmt.Sprintf("int64(%d)", int64(n)), nil
+	case "Float64":
+		n, ok := v.(float64)
+		if !ok {
+			return "", fmt.Errorf("expected a number, got %v", v)
+		}
+		return fmt.Sprintf("float64(%s)", strconv.FormatFloat(n, 'g', -1, 64)), nil
+	case "String":
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string, got %v", v)
+		}
+		return strconv.Quote(s), nil
+	case "Bool":
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("expected a bool, got %v", v)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return anyLiteral(v), nil
+}
+
+// anyLiteral returns a value decoded from JSON as a Go literal of the
+// types bloblang uses, with whole numbers as int64
+func anyLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return fmt.Sprintf("int64(%d)", int64(v))
+		}
+		return fmt.Sprintf("float64(%s)", strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		return strconv.Quote(v)
+	case []any:
+		elems := make([]string, len(v))
+		for i, e := range v {
+			elems[i] = anyLiteral(e)
+		}
+		return "[]any{" + strings.Join(elems, ", ") + "}"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = fmt.Sprintf("%q: %s", k, anyLiteral(v[k]))
+		}
+		return "map[string]any{" + strings.Join(elems, ", ") + "}"
+	}
+	return fmt.Sprintf("%#v", v)
+}
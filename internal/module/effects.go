@@ -0,0 +1,358 @@
+package module
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// effectRule classifies the functions whose qualified name, such as
+// os.Remove or math/rand.Intn, matches Pattern, a glob as in Filter
+type effectRule struct {
+	Pattern string
+	Effects string
+}
+
+// effectRules is the denylist functions are classified by, first
+// match wins. A rule naming a function of the module itself settles
+// its class, the others are followed through its call graph.
+var effectRules = []effectRule{
+	{"os.Exit", EffectsDangerous},
+	{"os.Remove*", EffectsDangerous},
+	{"os.Rename", EffectsDangerous},
+	{"os.Setenv", EffectsDangerous},
+	{"os.Unsetenv", EffectsDangerous},
+	{"os.Clearenv", EffectsDangerous},
+	{"os.Ch*", EffectsDangerous},
+	{"os.Lchown", EffectsDangerous},
+	{"os.Mkdir*", EffectsDangerous},
+	{"os.Create*", EffectsDangerous},
+	{"os.OpenFile", EffectsDangerous},
+	{"os.WriteFile", EffectsDangerous},
+	{"os.CopyFS", EffectsDangerous},
+	{"os.Symlink", EffectsDangerous},
+	{"os.Link", EffectsDangerous},
+	{"os.Truncate", EffectsDangerous},
+	{"os.StartProcess", EffectsDangerous},
+	{"os.FindProcess", EffectsDangerous},
+	{"os.Pipe", EffectsDangerous},
+	{"os.NewFile", EffectsDangerous},
+	{"os.Getenv", EffectsImpure},
+	{"os.LookupEnv", EffectsImpure},
+	{"os.Environ", EffectsImpure},
+	{"os.ExpandEnv", EffectsImpure},
+	{"os.Get*", EffectsImpure},
+	{"os.Hostname", EffectsImpure},
+	{"os.Executable", EffectsImpure},
+	{"os.User*", EffectsImpure},
+	{"os.TempDir", EffectsImpure},
+	{"os.Open", EffectsImpure},
+	{"os.OpenInRoot", EffectsImpure},
+	{"os.OpenRoot", EffectsImpure},
+	{"os.ReadFile", EffectsImpure},
+	{"os.ReadDir", EffectsImpure},
+	{"os.Stat", EffectsImpure},
+	{"os.Lstat", EffectsImpure},
+	{"os.Readlink", EffectsImpure},
+	{"os/exec.*", EffectsDangerous},
+	{"os/signal.*", EffectsDangerous},
+	{"plugin.*", EffectsDangerous},
+	{"syscall.Get*", EffectsImpure},
+	{"syscall.Environ", EffectsImpure},
+	{"syscall.*stat*", EffectsImpure},
+	{"syscall.Open", EffectsImpure},
+	{"syscall.Read*", EffectsImpure},
+	{"syscall.Pread", EffectsImpure},
+	{"syscall.Seek", EffectsImpure},
+	{"syscall.Close", EffectsImpure},
+	{"syscall.Uname", EffectsImpure},
+	{"syscall.Sysctl*", EffectsImpure},
+	{"syscall.*", EffectsDangerous},
+	{"net.Dial*", EffectsDangerous},
+	{"net.Listen*", EffectsDangerous},
+	{"net.File*", EffectsDangerous},
+	{"net.Lookup*", EffectsImpure},
+	{"net.Resolve*", EffectsImpure},
+	{"net.Interface*", EffectsImpure},
+	{"net/http.Get", EffectsDangerous},
+	{"net/http.Head", EffectsDangerous},
+	{"net/http.Post*", EffectsDangerous},
+	{"net/http.Serve*", EffectsDangerous},
+	{"net/http.ListenAndServe*", EffectsDangerous},
+	{"net/smtp.*", EffectsDangerous},
+	{"log.Fatal*", EffectsDangerous},
+	{"log.Panic*", EffectsDangerous},
+	{"log.Set*", EffectsDangerous},
+	{"log.Print*", EffectsImpure},
+	{"fmt.Print*", EffectsImpure},
+	{"fmt.Scan*", EffectsImpure},
+	{"runtime.Goexit", EffectsDangerous},
+	{"runtime.GOMAXPROCS", EffectsDangerous},
+	{"runtime.GC", EffectsDangerous},
+	{"runtime.Set*", EffectsDangerous},
+	{"runtime.Breakpoint", EffectsDangerous},
+	{"runtime.LockOSThread", EffectsDangerous},
+	{"runtime.Num*", EffectsImpure},
+	{"runtime.Caller*", EffectsImpure},
+	{"runtime.Stack", EffectsImpure},
+	{"runtime.ReadMemStats", EffectsImpure},
+	{"runtime/debug.Set*", EffectsDangerous},
+	{"runtime/debug.FreeOSMemory", EffectsDangerous},
+	{"runtime/debug.WriteHeapDump", EffectsDangerous},
+	{"time.Now", EffectsImpure},
+	{"time.Since", EffectsImpure},
+	{"time.Until", EffectsImpure},
+	{"time.Sleep", EffectsImpure},
+	{"time.After*", EffectsImpure},
+	{"time.Tick", EffectsImpure},
+	{"time.New*er", EffectsImpure},
+	{"time.LoadLocation", EffectsImpure},
+	{"math/rand.*", EffectsImpure},
+	{"math/rand/v2.*", EffectsImpure},
+	{"crypto/rand.*", EffectsImpure},
+}
+
+type effectMatcher struct {
+	re      *regexp.Regexp
+	effects string
+}
+
+var effectMatchers = compileEffectRules(effectRules)
+
+func compileEffectRules(rules []effectRule) []effectMatcher {
+	matchers := make([]effectMatcher, len(rules))
+	for i, r := range rules {
+		re, err := compilePattern(r.Pattern)
+		if err != nil {
+			panic(err)
+		}
+		matchers[i] = effectMatcher{re: re, effects: r.Effects}
+	}
+	return matchers
+}
+
+// ruleEffects returns the class effectRules give the function
+// qualified, or "" when no rule matches
+func ruleEffects(qualified string) string {
+	for _, m := range effectMatchers {
+		if m.re.MatchString(qualified) {
+			return m.effects
+		}
+	}
+	return ""
+}
+
+// effectRank orders the classes from harmless to dangerous
+var effectRank = map[string]int{EffectsPure: 0, EffectsUnknown: 1, EffectsImpure: 2, EffectsDangerous: 3}
+
+// effect is the class of a function and what earned it that class
+type effect struct {
+	Effects string
+	Cause   string
+}
+
+// callGraph maps the functions of a package to the functions they
+// call, local ones by name and others by qualified name, and to the
+// first method they call, if any
+type callGraph struct {
+	local    map[string][]string
+	external map[string][]string
+	methods  map[string]string
+}
+
+// parseCallGraph parses the Go files of the package in dir that build
+// on this platform
+func parseCallGraph(dir string) (*callGraph, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return buildCallGraph(files), nil
+}
+
+// buildCallGraph collects the calls made by the package-level
+// functions of files. Methods are not followed, as their receivers
+// can't be told apart without type checking, so a function calling
+// one is recorded as doing so. Calls of func values, conversions and
+// builtins are left out.
+func buildCallGraph(files []*ast.File) *callGraph {
+	g := &callGraph{local: map[string][]string{}, external: map[string][]string{}, methods: map[string]string{}}
+
+	funcs := map[string]bool{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+				funcs[fd.Name.Name] = true
+				g.local[fd.Name.Name] = nil
+			}
+		}
+	}
+
+	for _, file := range files {
+		imports := map[string]string{}
+		for _, spec := range file.Imports {
+			importPath := spec.Path.Value[1 : len(spec.Path.Value)-1]
+			name := runtimePackageName(importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = importPath
+		}
+
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Body == nil {
+				continue
+			}
+
+			name := fd.Name.Name
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+
+				switch fun := ast.Unparen(call.Fun).(type) {
+				case *ast.Ident:
+					if funcs[fun.Name] && fun.Name != name {
+						g.local[name] = append(g.local[name], fun.Name)
+					}
+				case *ast.SelectorExpr:
+					if x, ok := fun.X.(*ast.Ident); ok {
+						if importPath, ok := imports[x.Name]; ok {
+							g.external[name] = append(g.external[name], importPath+"."+fun.Sel.Name)
+							return true
+						}
+					}
+					if _, ok := g.methods[name]; !ok {
+						g.methods[name] = fun.Sel.Name
+					}
+				}
+				return true
+			})
+		}
+	}
+	return g
+}
+
+// classify returns the class of every function of the package
+// importPath with the call graph g. A function named by a rule takes
+// its class, the others the most dangerous class of what they call,
+// followed through the functions of the package. A function calling
+// a method is at least unknown, as the method isn't followed.
+func (g *callGraph) classify(importPath string) map[string]effect {
+	effects := map[string]effect{}
+	fixed := map[string]bool{}
+
+	names := []string{}
+	for name := range g.local {
+		names = append(names, name)
+	}
+	for name := range g.external {
+		if _, ok := g.local[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		qualified := importPath + "." + name
+		if e := ruleEffects(qualified); e != "" {
+			effects[name] = effect{Effects: e, Cause: qualified + " is denylisted"}
+			fixed[name] = true
+			continue
+		}
+
+		effects[name] = effect{Effects: EffectsPure}
+		if method, ok := g.methods[name]; ok {
+			effects[name] = effect{Effects: EffectsUnknown, Cause: "it calls the method " + method}
+		}
+		for _, callee := range g.external[name] {
+			e := ruleEffects(callee)
+			if e != "" && effectRank[e] > effectRank[effects[name].Effects] {
+				effects[name] = effect{Effects: e, Cause: "it calls " + callee}
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, name := range names {
+			if fixed[name] {
+				continue
+			}
+			for _, callee := range g.local[name] {
+				e, ok := effects[callee]
+				if !ok || effectRank[e.Effects] <= effectRank[effects[name].Effects] {
+					continue
+				}
+				cause := "it calls " + importPath + "." + callee
+				if !fixed[callee] {
+					cause = e.Cause
+				}
+				effects[name] = effect{Effects: e.Effects, Cause: cause}
+				changed = true
+			}
+		}
+	}
+	return effects
+}
+
+// analyzeEffects classifies the functions of the module from its
+// sources. Without them, functions are classified by effectRules
+// alone, those no rule names being unknown.
+func (mod *Module) analyzeEffects() map[string]effect {
+	dir, err := getSourceDir(mod.Path)
+	if err == nil {
+		var g *callGraph
+		if g, err = parseCallGraph(dir); err == nil {
+			return g.classify(mod.Path)
+		}
+	}
+	log.Printf("%s: no call graph, classifying side effects by name only: %s\n", mod.GetName(), err)
+	return map[string]effect{}
+}
+
+// setEffects classifies f as pure, unknown, impure or dangerous, the
+// project config having the last word, and returns why f is left out
+// in safe mode, or ""
+func (mod *Module) setEffects(f *Function) string {
+	e, ok := mod.effects[f.Name]
+	if !ok {
+		e = effect{Effects: EffectsUnknown, Cause: "its sources weren't analyzed"}
+		qualified := mod.Path + "." + f.Name
+		if r := ruleEffects(qualified); r != "" {
+			e = effect{Effects: r, Cause: qualified + " is denylisted"}
+		}
+	}
+
+	if fc := mod.functionConfig(f.Name); fc != nil && fc.Effects != "" {
+		e = effect{Effects: fc.Effects, Cause: "the config says so"}
+	}
+
+	f.Effects = e.Effects
+
+	if e.Effects == EffectsDangerous {
+		if mod.Options.Safe {
+			return fmt.Sprintf("it is dangerous, %s", e.Cause)
+		}
+		log.Printf("%s: Function %s is dangerous, %s\n", mod.GetName(), f.Name, e.Cause)
+	}
+	return ""
+}
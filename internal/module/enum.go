@@ -0,0 +1,229 @@
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Enum is a named integer or string type with exported constants of
+// that type, such as time.Month. Its params also accept the constant
+// names and its results are rendered as names.
+type Enum struct {
+	// Type is the qualified type, e.g. time.Month
+	Type string
+	// Underlying is the type it is declared as, e.g. int
+	Underlying string
+	// Names are the constants of the type in declaration order
+	Names []string
+	// Import is the package to import when Type is used outside of it
+	Import string
+}
+
+// builtinEnums cover stdlib enums that appear in the signatures of
+// other packages
+var builtinEnums = []Enum{
+	{
+		Type:       "time.Month",
+		Underlying: "int",
+		Names: []string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		Import: "time",
+	},
+	{
+		Type:       "time.Weekday",
+		Underlying: "int",
+		Names:      []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		Import:     "time",
+	},
+}
+
+var (
+	typePattern  = regexp.MustCompile(`^type (?P<name>[A-Z]\w*) (?P<underlying>\w+)$`)
+	constPattern = regexp.MustCompile(`^\s*(?P<name>[A-Za-z_]\w*)(?:\s+(?P<type>[\w.]+))?(?:\s*=\s*(?P<value>.*))?$`)
+	// iotaPattern matches the values of sequential lists such as
+	// time.Month, rather than of flags (1 << iota) or quantities
+	iotaPattern = regexp.MustCompile(`^(?:\d+\s*\+\s*)?iota(?:\s*[+-]\s*\d+)?$`)
+)
+
+// parseType returns the name and underlying type of a declaration
+// such as "type Month int", if the underlying type can be an enum
+func parseType(line string) (string, string, bool) {
+	match := typePattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", false
+	}
+
+	underlying := match[2]
+	if underlying != "string" && underlying != "byte" && underlying != "rune" && !slices.Contains(integers, underlying) {
+		return "", "", false
+	}
+	return match[1], underlying, true
+}
+
+// parseConst parses a line of a const block. Untyped constants take
+// the type of the one before them, as go doc groups a block under the
+// type of its first constant, so Microsecond = 1000 * Nanosecond is a
+// Duration too.
+func parseConst(line string, prev Constant) (Constant, bool) {
+	// if "//" exists within a string this will cause a problem
+	line, _, _ = strings.Cut(line, "//")
+
+	match := constPattern.FindStringSubmatch(strings.TrimRight(line, " \t"))
+	if match == nil {
+		return Constant{}, false
+	}
+
+	c := Constant{Name: match[1], Type: match[2], Value: match[3]}
+	if c.Type == "" {
+		c.Type = prev.Type
+	}
+	return c, true
+}
+
+// buildEnums collects the local string types and iota listed integer
+// types that have exported constants. Quantities such as time.Duration
+// are left out, as their values are rarely one of the constants.
+func (mod *Module) buildEnums(types map[string]string) {
+	mod.Enums = nil
+
+	typeOf := map[string]string{}
+	sequential := map[string]bool{}
+	for _, c := range mod.Constants {
+		if c.Type != "" {
+			typeOf[c.Name] = c.Type
+		}
+		if iotaPattern.MatchString(c.Value) {
+			sequential[c.Type] = true
+		}
+	}
+
+	// aliases such as Crimson = Red have the type of their value. They
+	// are resolved once every block is parsed, as go doc prints them
+	// before the type the value is declared with, and are listed after
+	// the other constants so names render as the original.
+	constants := []Constant{}
+	aliases := []Constant{}
+	for _, c := range mod.Constants {
+		if c.Type != "" {
+			constants = append(constants, c)
+		} else if t, ok := typeOf[c.Value]; ok {
+			c.Type = t
+			aliases = append(aliases, c)
+		}
+	}
+
+	for _, c := range append(constants, aliases...) {
+		underlying, ok := types[c.Type]
+		if !ok || c.Name == "_" || !unicode.IsUpper([]rune(c.Name)[0]) {
+			continue
+		}
+		if underlying != "string" && !sequential[c.Type] {
+			continue
+		}
+
+		qualified := mod.Name + "." + c.Type
+
+		i := slices.IndexFunc(mod.Enums, func(e Enum) bool { return e.Type == qualified })
+		if i < 0 {
+			mod.Enums = append(mod.Enums, Enum{Type: qualified, Underlying: underlying})
+			i = len(mod.Enums) - 1
+		}
+		mod.Enums[i].Names = append(mod.Enums[i].Names, c.Name)
+	}
+}
+
+// enumFor returns the enum typeStr refers to, or nil
+func (mod *Module) enumFor(typeStr string) *Enum {
+	qualified := mod.qualifyType(typeStr)
+
+	for i, e := range mod.Enums {
+		if e.Type == qualified {
+			return &mod.Enums[i]
+		}
+	}
+
+	for i, e := range builtinEnums {
+		if e.Type == typeStr || e.Type == qualified {
+			return &builtinEnums[i]
+		}
+	}
+	return nil
+}
+
+// enumVar names the generated table holding the constants of e.
+// Tables of enums from other packages are prefixed with the module
+// name, as every module generated into a directory may declare one.
+func (mod *Module) enumVar(e *Enum) string {
+	pkg, name, _ := strings.Cut(e.Type, ".")
+	if pkg != mod.Name {
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return mod.Name + name + "Consts"
+}
+
+// enumConversion returns the helper call that accepts a name or a
+// value of the underlying type for an enum param
+func (mod *Module) enumConversion(e *Enum, name string, expr string) string {
+	helper := "mod2blob.EnumInt"
+	if e.Underlying == "string" {
+		helper = "mod2blob.EnumString"
+	}
+	return fmt.Sprintf("%s(%q, %s, %s)", helper, name, expr, mod.enumVar(e))
+}
+
+// usedEnums returns the enums the generated functions refer to
+func (mod *Module) usedEnums() []*Enum {
+	enums := []*Enum{}
+
+	add := func(e *Enum) {
+		if e != nil && !slices.Contains(enums, e) {
+			enums = append(enums, e)
+		}
+	}
+
+	for _, f := range mod.Map["function"] {
+		for _, arg := range f.Args {
+			add(mod.enumFor(arg.Type))
+		}
+		if mod.Options.EnumResults == EnumResultsName {
+			for _, ret := range f.Return {
+				add(mod.enumFor(ret.Type))
+			}
+		}
+	}
+	return enums
+}
+
+// enumTables returns the declarations of the constant tables of the
+// enums the generated functions refer to
+func (mod *Module) enumTables() string {
+	var b strings.Builder
+
+	for _, e := range mod.usedEnums() {
+		pkg, _, _ := strings.Cut(e.Type, ".")
+
+		fmt.Fprintf(&b, "var %s = []mod2blob.EnumConst[%s]{\n", mod.enumVar(e), e.Type)
+		for _, name := range e.Names {
+			fmt.Fprintf(&b, "{Name: %q, Value: %s.%s},\n", name, pkg, name)
+		}
+		b.WriteString("}\n\n")
+	}
+	return b.String()
+}
+
+// enumResult returns the expression rendering an enum result as the
+// name of its constant, or an empty string when typeStr isn't an enum
+// or results are kept numeric
+func (mod *Module) enumResult(funcName string, typeStr string, expr string) string {
+	e := mod.enumFor(typeStr)
+	if e == nil || mod.Options.EnumResults != EnumResultsName {
+		return ""
+	}
+	return fmt.Sprintf("mod2blob.EnumName(%q, %s, %s, %q, %q)",
+		funcName, expr, mod.enumVar(e), mod.Options.NonFinite, mod.Options.BigUint)
+}
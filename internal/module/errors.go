@@ -10,4 +10,7 @@ var (
 	ErrEmptyString      = errors.New("string is empty")
 	ErrInvalidArguments = errors.New("invalid function arguments")
 	ErrCloneFailed      = errors.New("git clone failed")
+	ErrInvalidOption    = errors.New("invalid option value")
+	ErrInvalidConfig    = errors.New("invalid config file")
+	ErrNameCollision    = errors.New("generated function names collide")
 )
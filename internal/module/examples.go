@@ -0,0 +1,274 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// exampleResults are the result types whose printed form is also how
+// bloblang renders them, so the output of an upstream example can be
+// compared with the output of the mapping
+var exampleResults = append([]string{"string", "bool", "float64"}, integers...)
+
+// getSourceDir returns the directory holding the sources and tests of
+// moduleURL, under GOROOT for runtime packages
+func getSourceDir(moduleURL string) (string, error) {
+	if strings.Count(moduleURL, "/") > 1 {
+		return getModuleSrcPath(moduleURL)
+	}
+
+	goRoot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(strings.TrimSpace(string(goRoot)), "src", moduleURL), nil
+}
+
+// parseExamples returns the Example functions of the test files in
+// dir
+func parseExamples(dir string) ([]*doc.Example, error) {
+	files, err := filepath.Glob(path.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	parsed := []*ast.File{}
+	for _, f := range files {
+		file, err := parser.ParseFile(fset, f, nil, parser.ParseComments)
+		if err != nil {
+			log.Println(f + ": " + err.Error())
+			continue
+		}
+		parsed = append(parsed, file)
+	}
+	return doc.Examples(parsed...), nil
+}
+
+// addExamples adds the upstream examples of the module to the
+// generated functions they call
+func (mod *Module) addExamples() {
+	dir, err := getSourceDir(mod.Path)
+	if err != nil {
+		log.Printf("%s: no examples: %s\n", mod.GetName(), err)
+		return
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		log.Printf("%s: no examples: %s\n", mod.GetName(), err)
+		return
+	}
+
+	examples, err := parseExamples(dir)
+	if err != nil {
+		log.Printf("%s: no examples: %s\n", mod.GetName(), err)
+		return
+	}
+
+	mod.harvestExamples(examples)
+}
+
+// harvestExamples translates the simple cases among examples: an
+// example made up of fmt.Println calls printing one line each, where
+// a call prints a single result of a module function given literal
+// args. The printed line becomes the expected output of the mapping.
+func (mod *Module) harvestExamples(examples []*doc.Example) {
+	for _, ex := range examples {
+		body, ok := ex.Code.(*ast.BlockStmt)
+		if !ok || ex.Unordered || ex.EmptyOutput {
+			continue
+		}
+
+		// every statement prints at least a line, so as many lines as
+		// statements means each printed one. A string result spanning
+		// lines would otherwise shift the output of the calls after it.
+		output := strings.Split(strings.TrimSuffix(ex.Output, "\n"), "\n")
+		if len(output) != len(body.List) || !onlyPrintln(body.List) {
+			continue
+		}
+
+		for i, stmt := range body.List {
+			f, mapping, ok := mod.exampleMapping(stmt)
+			if !ok || !validExampleOutput(f, output[i]) {
+				continue
+			}
+
+			if slices.ContainsFunc(f.Examples, func(e Example) bool { return e.Mapping == mapping }) {
+				continue
+			}
+
+			f.Examples = append(f.Examples, Example{
+				Summary: strings.TrimSpace(ex.Doc),
+				Mapping: mapping,
+				Results: [][2]string{{"{}", output[i]}},
+			})
+			log.Printf("%s: Added example %s to %s\n", mod.GetName(), mapping, f.Name)
+		}
+	}
+}
+
+// onlyPrintln reports whether every statement of stmts is a call of
+// fmt.Println
+func onlyPrintln(stmts []ast.Stmt) bool {
+	for _, stmt := range stmts {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			return false
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok || !isSelector(call.Fun, "fmt", "Println") {
+			return false
+		}
+	}
+	return true
+}
+
+// exampleMapping translates fmt.Println(mod.Func(args...)) into the
+// mapping calling the plugin of Func with the same args
+func (mod *Module) exampleMapping(stmt ast.Stmt) (*Function, string, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, "", false
+	}
+
+	print, ok := expr.X.(*ast.CallExpr)
+	if !ok || !isSelector(print.Fun, "fmt", "Println") || len(print.Args) != 1 {
+		return nil, "", false
+	}
+
+	call, ok := print.Args[0].(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, "", false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isSelector(call.Fun, mod.Name, sel.Sel.Name) {
+		return nil, "", false
+	}
+
+	f := mod.generated(sel.Sel.Name)
+	if f == nil || f.Dst != "" || f.Out != "" || isVariadic(*f) || len(f.Args) != len(call.Args) {
+		return nil, "", false
+	}
+
+	if len(f.Return) != 1 || !slices.Contains(exampleResults, f.Return[0].Type) {
+		return nil, "", false
+	}
+
+	args := make([]string, len(call.Args))
+	for i, a := range call.Args {
+		if args[i], ok = exampleArg(f.Args[i], a); !ok {
+			return nil, "", false
+		}
+	}
+	return f, "root = " + mod.pluginName(*f) + "(" + strings.Join(args, ", ") + ")", true
+}
+
+// validExampleOutput reports whether line is how bloblang renders
+// the result of f. fmt prints large and small floats with an exponent,
+// such as 1e+06, where bloblang prints 1000000, so those are left out.
+func validExampleOutput(f *Function, line string) bool {
+	switch t := f.Return[0].Type; {
+	case t == "string":
+		return true
+	case t == "bool":
+		return line == "true" || line == "false"
+	case t == "float64":
+		v, err := strconv.ParseFloat(line, 64)
+		return err == nil && !math.IsInf(v, 0) && !math.IsNaN(v) && !strings.ContainsAny(line, "eE")
+	default:
+		_, err := strconv.ParseInt(line, 10, 64)
+		return err == nil
+	}
+}
+
+// exampleArg returns the bloblang literal for the Go literal passed
+// as arg, or false when the value isn't a literal of its type
+func exampleArg(arg Arg, expr ast.Expr) (string, bool) {
+	neg := ""
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.SUB {
+		neg, expr = "-", u.X
+	}
+
+	if ident, ok := expr.(*ast.Ident); ok && arg.Type == "bool" && neg == "" {
+		return ident.Name, ident.Name == "true" || ident.Name == "false"
+	}
+
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok {
+		return "", false
+	}
+
+	isFloat := arg.Type == "float64" || arg.Type == "float32"
+	isInt := slices.Contains(integers, arg.Type)
+
+	switch {
+	case lit.Kind == token.INT && (isInt || isFloat):
+		v, err := strconv.ParseInt(lit.Value, 0, 64)
+		return neg + strconv.FormatInt(v, 10), err == nil
+	case lit.Kind == token.FLOAT && isFloat:
+		v, err := strconv.ParseFloat(lit.Value, 64)
+		return neg + strconv.FormatFloat(v, 'f', -1, 64), err == nil
+	case lit.Kind == token.STRING && arg.Type == "string" && neg == "":
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return "", false
+		}
+
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(s); err != nil {
+			return "", false
+		}
+		return strings.TrimSuffix(b.String(), "\n"), true
+	}
+	return "", false
+}
+
+// isSelector reports whether expr is pkg.name
+func isSelector(expr ast.Expr, pkg string, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg
+}
+
+// hasExamples reports whether any of funcs has an example with
+// results to test
+func hasExamples(funcs []*Function) bool {
+	for _, f := range funcs {
+		for _, ex := range f.Examples {
+			if len(ex.Results) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generated returns the generated function called name, or nil
+func (mod *Module) generated(name string) *Function {
+	for _, f := range mod.Map["function"] {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package module
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Filter matches functions by name, signature and doc comment. Each
+// field is a glob, where * matches any text and ? a single character,
+// or a regexp between slashes such as /^Must/. A function matches
+// when every field that is set matches. Names are matched both bare
+// and qualified with the module, so Must* and strings.Must* work.
+type Filter struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Doc       string `json:"doc"`
+}
+
+// filterMatcher is a Filter with its patterns compiled, nil for the
+// fields that aren't set
+type filterMatcher struct {
+	filter    Filter
+	name      *regexp.Regexp
+	signature *regexp.Regexp
+	doc       *regexp.Regexp
+}
+
+// compilePattern compiles a glob, or a regexp between slashes
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+
+	glob := regexp.QuoteMeta(pattern)
+	glob = strings.ReplaceAll(glob, `\*`, ".*")
+	glob = strings.ReplaceAll(glob, `\?`, ".")
+	return regexp.Compile("(?s)^" + glob + "$")
+}
+
+// compile compiles the patterns of f
+func (f Filter) compile() (*filterMatcher, error) {
+	if f.Name == "" && f.Signature == "" && f.Doc == "" {
+		return nil, fmt.Errorf("one of name, signature or doc is required")
+	}
+
+	m := &filterMatcher{filter: f}
+
+	var err error
+	for _, p := range []struct {
+		re      **regexp.Regexp
+		pattern string
+	}{{&m.name, f.Name}, {&m.signature, f.Signature}, {&m.doc, f.Doc}} {
+		*p.re, err = compilePattern(p.pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// matches reports whether fn of the module modName matches
+func (m *filterMatcher) matches(modName string, fn *Function) bool {
+	if m.name != nil && !m.name.MatchString(fn.Name) && !m.name.MatchString(modName+"."+fn.Name) {
+		return false
+	}
+	if m.signature != nil && !m.signature.MatchString(fn.Signature) {
+		return false
+	}
+	if m.doc != nil && !m.doc.MatchString(fn.Description) {
+		return false
+	}
+	return true
+}
+
+// String describes the filter in the skip report
+func (m *filterMatcher) String() string {
+	parts := []string{}
+	for _, p := range [][2]string{{"name", m.filter.Name}, {"signature", m.filter.Signature}, {"doc", m.filter.Doc}} {
+		if p[1] != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", p[0], p[1]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// compileFilters compiles the include and exclude lists of the config
+func (c *Config) compileFilters() error {
+	for _, list := range []struct {
+		name     string
+		filters  []Filter
+		matchers *[]*filterMatcher
+	}{{"include", c.Include, &c.include}, {"exclude", c.Exclude, &c.exclude}} {
+		*list.matchers = nil
+
+		for i, f := range list.filters {
+			m, err := f.compile()
+			if err != nil {
+				log.Printf("%s %d: %s\n", list.name, i, err)
+				return ErrInvalidConfig
+			}
+			*list.matchers = append(*list.matchers, m)
+		}
+	}
+	return nil
+}
+
+// filterReason returns why the include and exclude lists of the
+// project config leave f out, or an empty string if they don't
+func (mod *Module) filterReason(f *Function) string {
+	c := mod.Options.Config
+	if c == nil {
+		return ""
+	}
+
+	if len(c.include) > 0 {
+		included := false
+		for _, m := range c.include {
+			if m.matches(mod.Name, f) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return "it matches no include filter"
+		}
+	}
+
+	for _, m := range c.exclude {
+		if m.matches(mod.Name, f) {
+			return "it matches the exclude filter " + m.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package module
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// headerWidth is where the option list of a header is wrapped
+const headerWidth = 72
+
+// toolVersion returns the version of mod2blob from its build info,
+// with the commit it was built from when that is known
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	version := info.Main.Version
+	if version == "" {
+		version = "(devel)"
+	}
+
+	settings := map[string]string{}
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+
+	if rev := settings["vcs.revision"]; rev != "" && version == "(devel)" {
+		if len(rev) > 12 {
+			rev = rev[:12]
+		}
+		version += " " + rev
+		if settings["vcs.modified"] == "true" {
+			version += "-dirty"
+		}
+	}
+	return version
+}
+
+// flags returns the options as the command line flags giving them
+func (o Options) flags() []string {
+	flags := []string{}
+
+	add := func(name string, v any) {
+		flags = append(flags, fmt.Sprintf("-%s=%v", name, v))
+	}
+
+	if o.Prefix != "" {
+		add("prefix", o.Prefix)
+	}
+	add("naming", o.Naming)
+	add("builtin-clash", o.BuiltinClash)
+	add("non-finite", o.NonFinite)
+	add("big-uint", o.BigUint)
+	add("max-elements", o.MaxElements)
+	add("chan-wait", o.ChanWait)
+	add("iter-pairs", o.IterPairs)
+	add("enum-results", o.EnumResults)
+	add("results", o.Results)
+	add("init", o.Init)
+	add("safe", o.Safe)
+	add("fold", o.Fold)
+	add("recover", o.Recover)
+	add("memoize", o.Memoize)
+	add("max-string-bytes", o.MaxStringBytes)
+	add("max-array-elements", o.MaxArrayElements)
+	if o.Timeout > 0 {
+		add("timeout", o.Timeout)
+	}
+	if o.Config != nil && o.Config.path != "" {
+		add("config", o.Config.path)
+	}
+	return flags
+}
+
+// header returns body with the header marking it as generated put in
+// front, written as comments starting with marker. Besides the
+// standard "Code generated ... DO NOT EDIT." line it records where the
+// code came from: the module and its version, the version of mod2blob,
+// the options and a hash of body, so edits made by hand show.
+func (mod *Module) header(marker string, body []byte) []byte {
+	fields := []string{"module:   " + mod.Path}
+
+	if mod.Version != "" {
+		fields = append(fields, "version:  "+mod.Version)
+	}
+
+	fields = append(fields, "mod2blob: "+toolVersion())
+
+	line := "options: "
+	for _, flag := range mod.Options.flags() {
+		if len(line)+1+len(flag) > headerWidth && line != "options: " {
+			fields = append(fields, line)
+			line = "         "
+		}
+		line += " " + flag
+	}
+	fields = append(fields, line)
+
+	return generatedHeader(marker, "Code generated by mod2blob from "+mod.Path+". DO NOT EDIT.", fields, body)
+}
+
+// generatedHeader puts the comments starting with marker made of
+// title, fields and the hash of body in front of body
+func generatedHeader(marker string, title string, fields []string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+
+	lines := append([]string{title, ""}, fields...)
+	lines = append(lines, "sha256:   "+hex.EncodeToString(sum[:]))
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(marker+" "+l, " ") + "\n")
+	}
+	b.WriteString("\n")
+	b.Write(body)
+	return []byte(b.String())
+}
@@ -0,0 +1,169 @@
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// limits are the resource guards of a generated function, zero
+// meaning unlimited
+type limits struct {
+	MaxStringBytes   int
+	MaxArrayElements int
+	Timeout          time.Duration
+}
+
+// limits returns the guards of f, the options overridden by the
+// project config
+func (mod *Module) limits(f Function) limits {
+	l := limits{
+		MaxStringBytes:   mod.Options.MaxStringBytes,
+		MaxArrayElements: mod.Options.MaxArrayElements,
+		Timeout:          mod.Options.Timeout,
+	}
+
+	fc := mod.functionConfig(f.Name)
+	if fc == nil {
+		return l
+	}
+
+	if fc.MaxStringBytes != 0 {
+		l.MaxStringBytes = fc.MaxStringBytes
+	}
+	if fc.MaxArrayElements != 0 {
+		l.MaxArrayElements = fc.MaxArrayElements
+	}
+	if fc.Timeout != "" {
+		// validated when the config was loaded
+		l.Timeout, _ = time.ParseDuration(fc.Timeout)
+	}
+	return l
+}
+
+// countNames are the names of the integer args taken as sizing the
+// result of a call, such as the count of strings.Repeat
+var countNames = []string{"n", "count", "size", "length", "cap", "capacity", "width"}
+
+// counts returns the indexes among the params of f of the integer
+// args named like a count or size
+func counts(f Function) []string {
+	indexes := []string{}
+	for i, a := range params(f) {
+		if slices.Contains(countNames, strings.ToLower(a.Name)) && slices.Contains(integers, a.Type) {
+			indexes = append(indexes, strconv.Itoa(i))
+		}
+	}
+	return indexes
+}
+
+// guard returns the mod2blob.Limits literal guarding the calls of f,
+// or "" when f is unlimited
+func (mod *Module) guard(f Function) string {
+	l := mod.limits(f)
+
+	fields := []string{}
+	if l.MaxStringBytes > 0 {
+		fields = append(fields, fmt.Sprintf("MaxStringBytes: %d", l.MaxStringBytes))
+	}
+	if l.MaxArrayElements > 0 {
+		fields = append(fields, fmt.Sprintf("MaxArrayElements: %d", l.MaxArrayElements))
+	}
+	if l.Timeout > 0 {
+		fields = append(fields, fmt.Sprintf("Timeout: %d", l.Timeout))
+	}
+	if indexes := counts(f); len(indexes) > 0 && (l.MaxStringBytes > 0 || l.MaxArrayElements > 0) {
+		fields = append(fields, "Counts: []int{"+strings.Join(indexes, ", ")+"}")
+	}
+
+	if len(fields) == 0 {
+		return ""
+	}
+	return "mod2blob.Limits{" + strings.Join(fields, ", ") + "}"
+}
+
+// memoize returns the number of results of f to cache, 0 when f
+// isn't memoised. Only pure functions are.
+func (mod *Module) memoize(f Function) int {
+	if f.Effects != EffectsPure {
+		return 0
+	}
+
+	n := mod.Options.Memoize
+	if fc := mod.functionConfig(f.Name); fc != nil && fc.Memoize != 0 {
+		n = fc.Memoize
+	}
+	return n
+}
+
+// foldRules are the functions known to be pure, which neither write
+// into their args nor panic, that may be folded without the project
+// config saying they are pure
+var foldRules = []string{
+	"math.*",
+	"math/bits.Len*",
+	"math/bits.LeadingZeros*",
+	"math/bits.TrailingZeros*",
+	"math/bits.OnesCount*",
+	"math/bits.Reverse*",
+	"math/bits.RotateLeft*",
+	"strconv.Format*",
+	"strconv.Quote*",
+	"strconv.Unquote*",
+	"strconv.Parse*",
+	"strings.To*",
+	"strings.Trim*",
+	"strings.Has*",
+	"strings.Contains*",
+	"strings.Index*",
+	"strings.EqualFold",
+	"unicode.Is*",
+	"unicode.To*",
+	"unicode/utf8.Valid*",
+	"unicode/utf8.RuneLen",
+	"unicode/utf8.RuneCount*",
+}
+
+var foldMatchers = compileFoldRules(foldRules)
+
+func compileFoldRules(rules []string) []*regexp.Regexp {
+	matchers := make([]*regexp.Regexp, len(rules))
+	for i, r := range rules {
+		re, err := compilePattern(r)
+		if err != nil {
+			panic(err)
+		}
+		matchers[i] = re
+	}
+	return matchers
+}
+
+// fold reports whether f is called once in the constructor of its
+// plugin, so that calls with literal args are made when the mapping
+// is parsed. Only functions explicitly known to be pure are folded:
+// those the project config says are, and those in foldRules that
+// aren't found to be impure.
+func (mod *Module) fold(f Function) bool {
+	if !mod.Options.Fold {
+		return false
+	}
+
+	if fc := mod.functionConfig(f.Name); fc != nil && fc.Effects != "" {
+		return fc.Effects == EffectsPure
+	}
+
+	if f.Effects != EffectsPure && f.Effects != EffectsUnknown {
+		return false
+	}
+
+	qualified := mod.Path + "." + f.Name
+	for _, re := range foldMatchers {
+		if re.MatchString(qualified) {
+			return true
+		}
+	}
+	return false
+}
@@ -33,19 +33,92 @@ func (mod *Module) buildMap() error {
 	mod.Map = make(map[string][]*Function)
 
 	for _, f := range mod.Functions {
-		if !checkValidFunction(f) {
-			log.Printf("%s: Skipped function %+v Args:%v Return:%v\n", mod.GetName(), f.Name, f.Args, f.Return)
+		skip, err := mod.applyOverrides(f)
+		if err != nil {
+			return err
+		}
+
+		if skip {
+			mod.skip(f, "the config skips it")
+			continue
+		}
+
+		if reason := mod.filterReason(f); reason != "" {
+			mod.skip(f, reason)
+			continue
+		}
+
+		if !mod.checkValidFunction(f) {
+			mod.skip(f, fmt.Sprintf("unsupported types Args:%v Return:%v", f.Args, f.Return))
+			continue
+		}
+
+		if !mod.setOutput(f) {
+			mod.skip(f, "it returns nothing and writes into no args")
 			continue
 		}
 
-		if len(f.Args) > 0 {
-			_ = mod.addToMap("function", f)
-			log.Printf("%s: Added function %+v Args:%v Return:%v\n", mod.GetName(), f.Name, f.Args, f.Return)
+		if err := mod.setDefaults(f); err != nil {
+			return err
 		}
+
+		if fc := mod.functionConfig(f.Name); fc != nil {
+			f.Examples = append(f.Examples, fc.Examples...)
+		}
+
+		if len(f.Args) == 0 {
+			mod.skip(f, "it takes no args")
+			continue
+		}
+
+		if reason := mod.setEffects(f); reason != "" {
+			mod.skip(f, reason)
+			continue
+		}
+
+		_ = mod.addToMap("function", f)
+		log.Printf("%s: Added function %+v Args:%v Return:%v\n", mod.GetName(), f.Name, f.Args, f.Return)
 	}
 	return nil
 }
 
+// skip records that f is left out and why
+func (mod *Module) skip(f *Function, reason string) {
+	mod.Skipped = append(mod.Skipped, Skip{Function: f.Name, Reason: reason})
+	log.Printf("%s: Skipped function %s, %s\n", mod.GetName(), f.Name, reason)
+}
+
+// funcNamePattern finds the name of a function, not a method, and
+// whether type params follow it
+var funcNamePattern = regexp.MustCompile(`^func (\w+)([\[(])`)
+
+// skipUnparsed records a function whose signature parseFunction
+// rejects, such as a generic one. Methods aren't functions and are
+// left out silently.
+func (mod *Module) skipUnparsed(signature string, err error) {
+	match := funcNamePattern.FindStringSubmatch(signature)
+	if match == nil {
+		return
+	}
+
+	reason := fmt.Sprintf("its signature can't be parsed: %s", err)
+	if match[2] == "[" {
+		reason = "it is generic"
+	}
+	mod.skip(&Function{Name: match[1]}, reason)
+}
+
+// SkipReport lists the functions left out of the generated code
+func (mod *Module) SkipReport() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: generated %d functions, skipped %d", mod.GetName(), len(mod.Map["function"]), len(mod.Skipped))
+	for _, s := range mod.Skipped {
+		fmt.Fprintf(&b, "\n  %s: %s", s.Function, s.Reason)
+	}
+	return b.String()
+}
+
 func gitClone(moduleURL string) error {
 	packageDir, err := getModuleSrcPath(moduleURL)
 	if err != nil {
@@ -90,13 +163,26 @@ func getModuleSrcPath(moduleURL string) (string, error) {
 	return path.Join(goPath, "src", moduleURL), nil
 }
 
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// runtimePackageName returns the name of a standard library package,
+// the last element of its path not counting a major version, so
+// encoding/hex is hex and math/rand/v2 is rand
+func runtimePackageName(importPath string) string {
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name
+}
+
 func getModuleName(moduleURL string) (string, error) {
 	var moduleName string
 
 	// This should be better, if it is a runtime package
 	// then we dont need to look for the module name
 	if strings.Count(moduleURL, "/") < 2 {
-		return moduleURL, nil
+		return runtimePackageName(moduleURL), nil
 	}
 
 	modulePath, err := getModuleSrcPath(moduleURL)
@@ -145,12 +231,41 @@ func getModuleName(moduleURL string) (string, error) {
 	return moduleName, nil
 }
 
-func LoadModule(modulePath string, prefix string) (*Module, error) {
+// getModuleVersion returns the tag, or failing that the commit, the
+// checkout of moduleURL is at, and the Go version for runtime
+// packages. An empty string is returned when neither can be found.
+func getModuleVersion(moduleURL string) string {
+	var cmd *exec.Cmd
+
+	if strings.Count(moduleURL, "/") < 2 {
+		cmd = exec.Command("go", "env", "GOVERSION")
+	} else {
+		packageDir, err := getModuleSrcPath(moduleURL)
+		if err != nil {
+			return ""
+		}
+		cmd = exec.Command("git", "-C", packageDir, "describe", "--tags", "--always")
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		log.Println(moduleURL + ": no version found: " + err.Error())
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func LoadModule(modulePath string, opts Options) (*Module, error) {
 	var (
 		err    error
 		docStr []byte
 	)
 
+	err = opts.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	mod := &Module{}
 
 	// if module does not have slash, assume it is a runtime mod
@@ -179,7 +294,8 @@ func LoadModule(modulePath string, prefix string) (*Module, error) {
 	mod.raw = docStr
 	mod.Name = moduleName
 	mod.Path = modulePath
-	mod.Prefix = prefix
+	mod.Version = getModuleVersion(modulePath)
+	mod.Options = opts
 
 	err = mod.parseDoc()
 	if err != nil {
@@ -187,12 +303,17 @@ func LoadModule(modulePath string, prefix string) (*Module, error) {
 		return nil, err
 	}
 
+	mod.effects = mod.analyzeEffects()
+
 	// build a map of functions, methods etc.
 	err = mod.buildMap()
 	if err != nil {
 		return nil, err
 	}
 
+	mod.resolveBuiltins()
+	mod.addExamples()
+
 	return mod, nil
 }
 
@@ -205,67 +326,55 @@ func (mod *Module) GetPath() string {
 }
 
 func (mod *Module) GetPrefix() string {
-	return mod.Prefix
+	return mod.Options.Prefix
+}
+
+func (mod *Module) GetOptions() Options {
+	return mod.Options
 }
 
 func (mod *Module) parseDoc() error {
 	lines := strings.Split(string(mod.raw), "\n")
 
 	functions := []*Function{}
-	for i := 0; i < len(lines); i += 2 {
+	types := map[string]string{}
+	// every line is looked at, as doc comments span any number of
+	// lines and a declaration can start on any of them
+	for i := 0; i < len(lines); i++ {
 		if strings.HasPrefix(lines[i], "func") {
 			function, err := parseFunction(lines[i])
 			if err != nil {
+				mod.skipUnparsed(lines[i], err)
 				continue
 			}
 
-			// This only works with single line descriptions,
-			// fix to span multiple lines
-			function.Description = strings.TrimSpace(lines[i+1])
+			function.Description = parseDescription(lines, i)
+			function.Signature = lines[i]
 
 			functions = append(functions, function)
 
 		} else if strings.HasPrefix(lines[i], "const (") {
 			i++ // skip passed the const ( line
-			for i < len(lines) {
-				// if "//" exists within a string this will cause a problem
-				if strings.Contains(lines[i], "//") {
-					lines[i] = strings.Split(lines[i], "//")[0]
-				}
-				pattern := regexp.MustCompile(`\s+(?P<name>\S+)\s+=\s+(?P<value>.*)`)
-				match := pattern.FindStringSubmatch(lines[i])
-
-				if len(match) > 0 {
-					var (
-						name  string
-						value string
-					)
-
-					for j, expName := range pattern.SubexpNames() {
-						// log.Printf("'%8s'\t %d -> %s\n", name, i, match[i])
-						// skip matches that are empty strings
-						if match[j] == "" {
-							continue
-						}
-						switch expName {
-						case "name":
-							name = match[j]
-						case "value":
-							value = match[j]
-						}
-					}
-
-					mod.Constants = append(mod.Constants, Constant{Name: name, Value: value})
-				}
-				if strings.HasPrefix(lines[i], ")") {
-					break
+
+			prev := Constant{}
+			for i < len(lines) && !strings.HasPrefix(lines[i], ")") {
+				if c, ok := parseConst(lines[i], prev); ok {
+					mod.Constants = append(mod.Constants, c)
+					prev = c
 				}
 				i++
 			}
+		} else if line, ok := strings.CutPrefix(lines[i], "const "); ok {
+			if c, ok := parseConst(line, Constant{}); ok {
+				mod.Constants = append(mod.Constants, c)
+			}
+		} else if name, underlying, ok := parseType(lines[i]); ok {
+			types[name] = underlying
 		}
 	}
 
 	mod.Functions = functions
+	mod.buildEnums(types)
 
 	return nil
 }
@@ -276,16 +385,50 @@ func derefFunction(f *Function) Function {
 
 func (mod *Module) Generate(outputDir string) error {
 	customFuncs := map[string]any{
-		"benthosType":   toBenthosType,
+		"benthosType":   mod.paramType,
+		"convertArg":    mod.convertArg,
+		"convertRawArg": mod.convertRawArg,
+		"getter":        mod.getter,
+		"getImports":    mod.getImports,
+		"enumTables":    mod.enumTables,
+		"isOptional":    isOptional,
+		"isVariadic":    isVariadic,
+		"params":        params,
+		"passArg":       passArg,
+		"paramName":     paramName,
+		"checkOut":      mod.checkOut,
+		"prepareOut":    mod.prepareOut,
+		"returnOut":     mod.returnOut,
+		"returnResults": mod.returnResults,
 		"function":      derefFunction,
 		"getModulePath": mod.GetPath,
 		"getModuleName": mod.GetName,
 		"getPrefix":     mod.GetPrefix,
+		"pluginName":    mod.pluginName,
+		"hasExamples":   hasExamples,
+		"docComment":    docComment,
+		"paramDesc":     mod.paramDescription,
+		"specMeta":      mod.specMeta,
+		"getOptions":    mod.GetOptions,
+		"registerName":  mod.RegisterName,
+		"guard":         mod.guard,
+		"memoize":       mod.memoize,
+		"fold":          mod.fold,
 	}
 
+	// the runtime package is only imported when the generated code
+	// calls into it, which is known once it has been rendered
+	useRuntime := true
+	customFuncs["useRuntime"] = func() bool { return useRuntime }
+	customFuncs["runtimeImport"] = func() string { return runtimeImport }
+
 	if len(mod.Map["function"]) > 0 {
+		err := mod.checkNames(outputDir)
+		if err != nil {
+			return err
+		}
+
 		var (
-			err        error
 			source     bytes.Buffer
 			testSource bytes.Buffer
 		)
@@ -303,6 +446,16 @@ func (mod *Module) Generate(outputDir string) error {
 			panic(err)
 		}
 
+		if !runtimeCall.Match(source.Bytes()) {
+			useRuntime = false
+			source.Reset()
+
+			err = funcTmpl.Execute(&source, mod.Map["function"])
+			if err != nil {
+				panic(err)
+			}
+		}
+
 		var formatted []byte
 
 		formatted, err = format.Source(source.Bytes(), format.Options{ExtraRules: true})
@@ -310,13 +463,18 @@ func (mod *Module) Generate(outputDir string) error {
 			panic(err)
 		}
 
-		f, err := os.Create(path.Join(outputDir, getFileName(mod.Name)))
+		f, err := os.Create(path.Join(outputDir, mod.fileName(".go")))
 		if err != nil {
 			panic(err)
 		}
 		defer f.Close()
 
-		_, err = f.Write(formatted)
+		_, err = f.Write(mod.header("//", formatted))
+		if err != nil {
+			panic(err)
+		}
+
+		err = writeHelpers(outputDir)
 		if err != nil {
 			panic(err)
 		}
@@ -335,21 +493,62 @@ func (mod *Module) Generate(outputDir string) error {
 			panic(err)
 		}
 
-		testFile, err := os.Create(path.Join(outputDir, mod.Name+".yaml"))
+		testFile, err := os.Create(path.Join(outputDir, mod.fileName(".yaml")))
 		if err != nil {
 			panic(err)
 		}
 		defer testFile.Close()
 
-		_, err = testFile.Write(testSource.Bytes())
+		_, err = testFile.Write(mod.header("#", testSource.Bytes()))
 		if err != nil {
 			panic(err)
 		}
+
+		if hasExamples(mod.Map["function"]) {
+			err = mod.writeExamples(outputDir, customFuncs)
+			if err != nil {
+				panic(err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// writeExamples writes the benthos unit tests running the examples
+// of the generated functions
+func (mod *Module) writeExamples(outputDir string, customFuncs map[string]any) error {
+	var source bytes.Buffer
+
+	tmpl, err := template.New("examples").
+		Funcs(sprout.FuncMap()).
+		Funcs(customFuncs).
+		Parse(gen.Examples)
+	if err != nil {
+		return err
+	}
+
+	err = tmpl.Execute(&source, mod.Map["function"])
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(outputDir, mod.fileName("_examples.yaml")), mod.header("#", source.Bytes()), 0o644)
+}
+
+// writeHelpers writes the registry shared by all modules generated
+// into outputDir
+func writeHelpers(outputDir string) error {
+	formatted, err := format.Source([]byte(gen.Helpers), format.Options{ExtraRules: true})
+	if err != nil {
+		return err
+	}
+
+	formatted = generatedHeader("//", "Code generated by mod2blob. DO NOT EDIT.", []string{"mod2blob: " + toolVersion()}, formatted)
+
+	return os.WriteFile(path.Join(outputDir, helpersFileName), formatted, 0o644)
+}
+
 func (mod *Module) ListFunctions() []Function {
 	return nil
 }
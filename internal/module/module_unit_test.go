@@ -1,7 +1,15 @@
 package module
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -221,6 +229,29 @@ func Test_parseReturnArguments(t *testing.T) {
 			expected:   nil,
 			err:        ErrInvalidArguments,
 		},
+		{
+			definition: "iter.Seq2[int, string]",
+			expected: []Arg{
+				{
+					Type: "iter.Seq2[int, string]",
+				},
+			},
+			err: nil,
+		},
+		{
+			definition: "(ch <-chan int, n int)",
+			expected: []Arg{
+				{
+					Name: "ch",
+					Type: "<-chan int",
+				},
+				{
+					Name: "n",
+					Type: "int",
+				},
+			},
+			err: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -305,6 +336,28 @@ func Test_parseFunctionArguments(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			definition: "year int, month Month, day, hour int",
+			expected: []Arg{
+				{
+					Name: "year",
+					Type: "int",
+				},
+				{
+					Name: "month",
+					Type: "Month",
+				},
+				{
+					Name: "day",
+					Type: "int",
+				},
+				{
+					Name: "hour",
+					Type: "int",
+				},
+			},
+			err: nil,
+		},
 		{
 			definition: "([]string  float64)",
 			expected:   nil,
@@ -352,7 +405,7 @@ func Test_toBenthosType(t *testing.T) {
 		},
 		{
 			input:    "uint",
-			expected: "Int64",
+			expected: "Any",
 		},
 		{
 			input:    "uint32",
@@ -360,7 +413,11 @@ func Test_toBenthosType(t *testing.T) {
 		},
 		{
 			input:    "uint64",
-			expected: "Int64",
+			expected: "Any",
+		},
+		{
+			input:    "bool",
+			expected: "Bool",
 		},
 	}
 
@@ -371,3 +428,1376 @@ func Test_toBenthosType(t *testing.T) {
 		})
 	}
 }
+
+func Test_convertArg(t *testing.T) {
+	tests := []struct {
+		input    Arg
+		expected string
+	}{
+		{
+			input:    Arg{Name: "x", Type: "float64"},
+			expected: "xa := x",
+		},
+		{
+			input:    Arg{Name: "x", Type: "int8"},
+			expected: "xa, err := mod2blob.Int[int8](\"x\", x)\nif err != nil {\nreturn nil, err\n}",
+		},
+		{
+			input:    Arg{Name: "n", Type: "uint64"},
+			expected: "na, err := mod2blob.Uint[uint64](\"n\", n)\nif err != nil {\nreturn nil, err\n}",
+		},
+		{
+			input:    Arg{Name: "v", Type: "[]int32"},
+			expected: "va, err := mod2blob.Ints[int32](\"v\", v)\nif err != nil {\nreturn nil, err\n}",
+		},
+		{
+			input:    Arg{Name: "p", Type: "*int"},
+			expected: "pa, err := mod2blob.Optional(\"p\", p, mod2blob.AnyInt[int])\nif err != nil {\nreturn nil, err\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Type, func(t *testing.T) {
+			actual := convertArg(tt.input)
+			assert.Equal(t, actual, tt.expected)
+		})
+	}
+}
+
+func Test_convertResult(t *testing.T) {
+	tests := []struct {
+		options  Options
+		input    Arg
+		expected string
+	}{
+		{
+			options:  Options{NonFinite: NonFiniteNull, BigUint: BigUintNumber},
+			input:    Arg{Type: "float64"},
+			expected: `mod2blob.Float("math.Sqrt", r, "null")`,
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber},
+			input:    Arg{Type: "uint64"},
+			expected: "",
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintString},
+			input:    Arg{Type: "uint64"},
+			expected: "mod2blob.BigUint(r)",
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintString},
+			input:    Arg{Type: "string"},
+			expected: "",
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber},
+			input:    Arg{Type: "*string"},
+			expected: "mod2blob.Deref(r)",
+		},
+		{
+			options:  Options{NonFinite: NonFiniteNull, BigUint: BigUintNumber},
+			input:    Arg{Type: "[]float64"},
+			expected: `mod2blob.Normalise("math.Sqrt", r, "null", "number")`,
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber},
+			input:    Arg{Type: "any"},
+			expected: `mod2blob.Normalise("math.Sqrt", r, "error", "number")`,
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber, MaxElements: 100},
+			input:    Arg{Type: "iter.Seq[string]"},
+			expected: `mod2blob.Seq("math.Sqrt", r, 100, "error", "number")`,
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber, MaxElements: 100, IterPairs: IterPairsObject},
+			input:    Arg{Type: "iter.Seq2[string, int]"},
+			expected: `mod2blob.Seq2("math.Sqrt", r, 100, "object", "error", "number")`,
+		},
+		{
+			options:  Options{NonFinite: NonFiniteError, BigUint: BigUintNumber, MaxElements: 100, ChanWait: time.Second},
+			input:    Arg{Type: "<-chan int"},
+			expected: `mod2blob.Chan("math.Sqrt", r, 100, 1000000000, "error", "number")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Type, func(t *testing.T) {
+			mod := &Module{Options: tt.options}
+			actual := mod.convertResult("math.Sqrt", tt.input, "r")
+			assert.Equal(t, actual, tt.expected)
+		})
+	}
+}
+
+func Test_validateOptions(t *testing.T) {
+	opts := Options{}
+	assert.NilError(t, opts.validate())
+	assert.Equal(t, opts.NonFinite, NonFiniteError)
+	assert.Equal(t, opts.BigUint, BigUintNumber)
+
+	assert.Equal(t, opts.MaxElements, DefaultMaxElements)
+	assert.Equal(t, opts.ChanWait, DefaultChanWait)
+	assert.Equal(t, opts.IterPairs, IterPairsArray)
+
+	opts = Options{NonFinite: "zero"}
+	assert.Equal(t, opts.validate(), ErrInvalidOption)
+
+	opts = Options{MaxElements: -1}
+	assert.Equal(t, opts.validate(), ErrInvalidOption)
+
+	opts = Options{ChanWait: -time.Second}
+	assert.Equal(t, opts.validate(), ErrInvalidOption)
+
+	opts = Options{IterPairs: "map"}
+	assert.Equal(t, opts.validate(), ErrInvalidOption)
+}
+
+func Test_checkValidFunctionPointers(t *testing.T) {
+	tests := []struct {
+		input    Arg
+		expected bool
+	}{
+		{
+			input:    Arg{Name: "x", Type: "*int"},
+			expected: true,
+		},
+		{
+			input:    Arg{Name: "x", Type: "*string"},
+			expected: true,
+		},
+		{
+			input:    Arg{Name: "x", Type: "*[]int"},
+			expected: false,
+		},
+		{
+			input:    Arg{Name: "x", Type: "*mat.Dense"},
+			expected: false,
+		},
+		{
+			input:    Arg{Name: "x", Type: "*big.Int"},
+			expected: true,
+		},
+		{
+			input:    Arg{Name: "err", Type: "error"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Type, func(t *testing.T) {
+			f := &Function{Name: "F", Args: []Arg{tt.input}}
+			assert.Equal(t, (&Module{}).checkValidFunction(f), tt.expected)
+		})
+	}
+}
+
+func Test_isVariadic(t *testing.T) {
+	tests := []struct {
+		definition string
+		expected   bool
+		valid      bool
+	}{
+		{
+			definition: "func Sprint(a ...any) string",
+			expected:   true,
+			valid:      true,
+		},
+		{
+			definition: "func Sprintf(format string, a ...interface{}) string",
+			expected:   true,
+			valid:      true,
+		},
+		{
+			definition: "func Max(x ...float64) float64",
+			expected:   false,
+		},
+		{
+			definition: "func Print(v any) string",
+			expected:   false,
+			valid:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			f, err := parseFunction(tt.definition)
+			assert.NilError(t, err)
+			assert.Equal(t, isVariadic(*f), tt.expected)
+			assert.Equal(t, (&Module{}).checkValidFunction(f), tt.valid)
+		})
+	}
+}
+
+func Test_LoadConfig(t *testing.T) {
+	tests := []struct {
+		config string
+		err    error
+	}{
+		{
+			config: "adapters:\n  - type: \"*mat.Dense\"\n    to_go: adapters.DenseFromAny\n",
+			err:    nil,
+		},
+		{
+			config: "adapters:\n  - type: \"*mat.Dense\"\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "adapters:\n  - type: ID\n    param: Uint64\n    to_go: ids.Parse\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  math.Pow:\n    name: power\n    params:\n      x: base\n",
+			err:    nil,
+		},
+		{
+			config: "functions:\n  math.Pow:\n    params:\n      x: Base\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  math.Pow:\n    name: math.pow\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  os.Getenv:\n    effects: pure\n",
+			err:    nil,
+		},
+		{
+			config: "functions:\n  os.Getenv:\n    effects: harmless\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  strings.Repeat:\n    max_string_bytes: 1024\n    timeout: 50ms\n",
+			err:    nil,
+		},
+		{
+			config: "functions:\n  strings.Repeat:\n    timeout: 50\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  strings.Repeat:\n    max_array_elements: -1\n",
+			err:    ErrInvalidConfig,
+		},
+		{
+			config: "functions:\n  strings.Fields:\n    memoize: -1\n",
+			err:    ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			configPath := path.Join(t.TempDir(), "mod2blob.yaml")
+			assert.NilError(t, os.WriteFile(configPath, []byte(tt.config), 0o600))
+
+			_, err := LoadConfig(configPath)
+			assert.Equal(t, err, tt.err)
+		})
+	}
+}
+
+func Test_adapterFor(t *testing.T) {
+	mod := &Module{
+		Name: "mat",
+		Options: Options{
+			Config: &Config{
+				Adapters: []Adapter{
+					{Type: "*mat.Dense", Param: "Any", ToGo: "adapters.DenseFromAny"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{
+			input:    "*mat.Dense",
+			expected: true,
+		},
+		{
+			input:    "*Dense",
+			expected: true,
+		},
+		{
+			input:    "Dense",
+			expected: false,
+		},
+		{
+			input:    "float64",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, mod.adapterFor(tt.input) != nil, tt.expected)
+		})
+	}
+
+	f := &Function{Name: "Det", Args: []Arg{{Name: "a", Type: "*Dense"}}}
+	assert.Equal(t, mod.checkValidFunction(f), true)
+	assert.Equal(t, mod.convertArg(f.Args[0]), "aa, err := adapters.DenseFromAny(a)\nif err != nil {\nreturn nil, err\n}")
+}
+
+func Test_builtinAdapters(t *testing.T) {
+	tests := []struct {
+		module   string
+		input    string
+		expected string
+	}{
+		{
+			module:   "big",
+			input:    "*Int",
+			expected: "mod2blob.ParseBigInt",
+		},
+		{
+			module:   "fixture",
+			input:    "net.IP",
+			expected: "mod2blob.ParseIP",
+		},
+		{
+			module:   "url",
+			input:    "*URL",
+			expected: "mod2blob.ParseURL",
+		},
+		{
+			module:   "uuid",
+			input:    "UUID",
+			expected: "mod2blob.ParseUUID",
+		},
+		{
+			module:   "orb",
+			input:    "Polygon",
+			expected: "orbjson.ToPolygon",
+		},
+		{
+			module:   "planar",
+			input:    "orb.Geometry",
+			expected: "orbjson.ToGeometry",
+		},
+		{
+			module:   "space",
+			input:    "Bound",
+			expected: "spacejson.ToBound",
+		},
+		{
+			module:   "h3",
+			input:    "[]Cell",
+			expected: "h3json.ToCells",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mod := &Module{Name: tt.module}
+			a := mod.adapterFor(tt.input)
+			assert.Assert(t, a != nil)
+			assert.Equal(t, a.ToGo, tt.expected)
+		})
+	}
+
+	// adapters from the config win over the built-in ones
+	mod := &Module{
+		Name: "big",
+		Options: Options{
+			Config: &Config{
+				Adapters: []Adapter{{Type: "*big.Int", Param: "Int64", ToGo: "adapters.BigFromInt"}},
+			},
+		},
+	}
+	assert.Equal(t, mod.adapterFor("*Int").ToGo, "adapters.BigFromInt")
+}
+
+func Test_getImports(t *testing.T) {
+	mod := &Module{
+		Name: "h3",
+		Path: "github.com/uber/h3-go/v4",
+		Map: map[string][]*Function{
+			"function": {
+				{Name: "CellToBoundary", Args: []Arg{{Name: "c", Type: "Cell"}}, Return: []Arg{{Type: "CellBoundary"}}},
+				{Name: "Distance", Args: []Arg{{Name: "p", Type: "orb.Point"}, {Name: "ip", Type: "net.IP"}}},
+			},
+		},
+	}
+
+	assert.DeepEqual(t, mod.getImports(), []string{
+		"github.com/nibbleshift/mod2blob/geo/h3json",
+		"github.com/nibbleshift/mod2blob/geo/orbjson",
+	})
+
+	// a boundary can be returned but not passed
+	f := &Function{Name: "Area", Args: []Arg{{Name: "b", Type: "CellBoundary"}}, Return: []Arg{{Type: "float64"}}}
+	assert.Equal(t, mod.checkValidFunction(f), false)
+}
+
+func Test_setOutput(t *testing.T) {
+	mod := &Module{
+		Name: "hex",
+		Functions: []*Function{
+			{Name: "EncodedLen", Args: []Arg{{Name: "n", Type: "int"}}, Return: []Arg{{Type: "int"}}},
+		},
+	}
+
+	tests := []struct {
+		input   Function
+		valid   bool
+		dst     string
+		dstSize string
+		out     string
+	}{
+		{
+			// sort.Float64s
+			input: Function{Name: "Float64s", Args: []Arg{{Name: "x", Type: "[]float64"}}},
+			valid: true,
+			out:   "x",
+		},
+		{
+			// floats.Scale, dst is read as well as written
+			input: Function{Name: "Scale", Args: []Arg{{Name: "c", Type: "float64"}, {Name: "dst", Type: "[]float64"}}},
+			valid: true,
+			out:   "dst",
+		},
+		{
+			input:   Function{Name: "Encode", Args: []Arg{{Name: "dst", Type: "[]byte"}, {Name: "src", Type: "[]byte"}}, Return: []Arg{{Type: "int"}}},
+			valid:   true,
+			dst:     "dst",
+			dstSize: "hex.EncodedLen(len(srca))",
+			out:     "dst",
+		},
+		{
+			input:   Function{Name: "Decode", Args: []Arg{{Name: "dst", Type: "[]byte"}, {Name: "src", Type: "[]byte"}}, Return: []Arg{{Type: "int"}, {Type: "error"}}},
+			valid:   true,
+			dst:     "dst",
+			dstSize: "len(srca)",
+			out:     "dst",
+		},
+		{
+			// floats.AddTo returns dst itself
+			input:   Function{Name: "AddTo", Args: []Arg{{Name: "dst", Type: "[]float64"}, {Name: "s", Type: "[]float64"}}, Return: []Arg{{Type: "[]float64"}}},
+			valid:   true,
+			dst:     "dst",
+			dstSize: "len(sa)",
+		},
+		{
+			// hex.AppendEncode appends to dst, which stays a param
+			input: Function{Name: "AppendEncode", Args: []Arg{{Name: "dst", Type: "[]byte"}, {Name: "src", Type: "[]byte"}}, Return: []Arg{{Type: "[]byte"}}},
+			valid: true,
+		},
+		{
+			input: Function{Name: "AppendDecode", Args: []Arg{{Name: "dst", Type: "[]byte"}, {Name: "src", Type: "[]byte"}}, Return: []Arg{{Type: "[]byte"}, {Type: "error"}}},
+			valid: true,
+		},
+		{
+			// only validates x, which isn't echoed back
+			input: Function{Name: "Validate", Args: []Arg{{Name: "x", Type: "[]float64"}}, Return: []Arg{{Type: "error"}}},
+			valid: true,
+		},
+		{
+			input: Function{Name: "Fill", Args: []Arg{{Name: "buf", Type: "[]byte"}}, Return: []Arg{{Type: "error"}}},
+			valid: true,
+			out:   "buf",
+		},
+		{
+			input: Function{Name: "Sum", Args: []Arg{{Name: "x", Type: "[]float64"}}, Return: []Arg{{Type: "float64"}}},
+			valid: true,
+		},
+		{
+			input: Function{Name: "Log", Args: []Arg{{Name: "s", Type: "string"}}},
+			valid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Name, func(t *testing.T) {
+			f := tt.input
+			assert.Equal(t, mod.setOutput(&f), tt.valid)
+			assert.Equal(t, f.Dst, tt.dst)
+			assert.Equal(t, f.DstSize, tt.dstSize)
+			assert.Equal(t, f.Out, tt.out)
+		})
+	}
+}
+
+func Test_passArg(t *testing.T) {
+	appendEncode := Function{Name: "AppendEncode", Args: []Arg{{Name: "dst", Type: "[]byte"}, {Name: "src", Type: "[]byte"}}}
+	assert.Equal(t, passArg(appendEncode, appendEncode.Args[0]), "dsta[:len(dsta):len(dsta)]")
+	assert.Equal(t, passArg(appendEncode, appendEncode.Args[1]), "srca")
+
+	encode := Function{Name: "Encode", Args: appendEncode.Args}
+	assert.Equal(t, passArg(encode, encode.Args[0]), "dsta")
+}
+
+func Test_paramName(t *testing.T) {
+	tests := map[string]string{
+		"x":       "x",
+		"bitSize": "bit_size",
+		"srcURL":  "src_url",
+		"URLPath": "url_path",
+		"utf8Str": "utf8_str",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, paramName(Arg{Name: input}), want)
+		})
+	}
+}
+
+func Test_parseDescription(t *testing.T) {
+	lines := strings.Split(`func Abs(x float64) float64
+    Abs returns the absolute value of x.
+
+    Special cases are:
+
+        Abs(±Inf) = +Inf
+        Abs(NaN) = NaN
+
+func Acos(x float64) float64
+    Acos returns the arccosine, in radians, of x.
+
+func Bare(x float64) float64
+func Next(x float64) float64`, "\n")
+
+	assert.Equal(t, parseDescription(lines, 0), "Abs returns the absolute value of x.\n\nSpecial cases are:\n\n    Abs(±Inf) = +Inf\n    Abs(NaN) = NaN")
+	assert.Equal(t, parseDescription(lines, 8), "Acos returns the arccosine, in radians, of x.")
+	assert.Equal(t, parseDescription(lines, 11), "")
+
+	assert.Equal(t, docComment("Abs returns x.\n\n    Abs(NaN) = NaN"), "// Abs returns x.\n//\n//     Abs(NaN) = NaN")
+}
+
+func Test_specMeta(t *testing.T) {
+	mod := &Module{Name: "math", Path: "math", Version: "go1.22.2", Options: Options{Config: &Config{Functions: map[string]FunctionConfig{
+		"math.Abs": {Version: "1.2.0"},
+	}}}}
+
+	f := Function{
+		Name:        "Abs",
+		Description: "Abs returns the absolute value of x.",
+		Examples: []Example{
+			{Summary: "Absolute value", Mapping: "root = abs(this.x)", Results: [][2]string{{`{"x":-2}`, "2"}}},
+		},
+	}
+
+	assert.Equal(t, mod.specMeta(f), `.
+Description("Abs returns the absolute value of x.").
+Category("math").
+Version("1.2.0").
+Example("Absolute value", "root = abs(this.x)", [2]string{"{\"x\":-2}", "2"})`)
+
+	// the module version isn't the version of the plugin
+	mod.Options.Config = nil
+	assert.Equal(t, mod.specMeta(Function{Name: "Abs"}), ".\nCategory(\"math\")")
+	assert.Equal(t, mod.specMeta(Function{Name: "Abs", Effects: EffectsImpure}), ".\nCategory(\"math\").\nImpure()")
+
+	assert.Equal(t, mod.paramDescription(Arg{Name: "x", Type: "float64"}), "")
+	assert.Equal(t, mod.paramDescription(Arg{Name: "x", Type: "*float64"}), "Omit it or pass null for nil.")
+	assert.Equal(t, mod.paramDescription(Arg{Name: "m", Type: "time.Month"}), "Takes the name of a constant (January, February, March, April, May, June, July, August, September, October, November, December) or its value.")
+}
+
+func Test_harvestExamples(t *testing.T) {
+	src := `package strings_test
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ExampleContains() {
+	fmt.Println(strings.Contains("seafood", "foo"))
+	fmt.Println(strings.Contains("", ""))
+	// Output:
+	// true
+	// true
+}
+
+func ExampleRepeat() {
+	fmt.Println("ba" + strings.Repeat("na", 2))
+	// Output: banana
+}
+
+func ExampleIndex() {
+	fmt.Println(strings.Index("chicken", "ken"), strings.Index("chicken", "dmr"))
+	fmt.Println(strings.Index("chicken", "dmr"))
+	// Output:
+	// 4 -1
+	// -1
+}
+
+func ExampleToUpper() {
+	s := "Gopher"
+	fmt.Println(strings.ToUpper(s))
+	// Output: GOPHER
+}
+
+func ExampleAbs() {
+	fmt.Println(strings.Abs(-2), strings.Abs(-0x10))
+	fmt.Println(strings.Abs(-1.5e1))
+	fmt.Println(strings.Abs(-1e6))
+	// Output:
+	// 2 16
+	// 15
+	// 1e+06
+}
+
+func ExampleToUpper_lines() {
+	_ = 0
+	fmt.Println(strings.ToUpper("a\nb"))
+	// Output:
+	// A
+	// B
+}
+`
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(path.Join(dir, "example_test.go"), []byte(src), 0o644))
+
+	examples, err := parseExamples(dir)
+	assert.NilError(t, err)
+
+	str := Arg{Name: "s", Type: "string"}
+	mod := &Module{Name: "strings", Options: Options{Prefix: "str_"}}
+	mod.Map = map[string][]*Function{"function": {
+		{Name: "Contains", Args: []Arg{str, {Name: "substr", Type: "string"}}, Return: []Arg{{Type: "bool"}}},
+		{Name: "Repeat", Args: []Arg{str, {Name: "count", Type: "int"}}, Return: []Arg{{Type: "string"}}},
+		{Name: "Index", Args: []Arg{str, {Name: "substr", Type: "string"}}, Return: []Arg{{Type: "int"}}},
+		{Name: "ToUpper", Args: []Arg{str}, Return: []Arg{{Type: "string"}}},
+		{Name: "Abs", Args: []Arg{{Name: "x", Type: "float64"}}, Return: []Arg{{Type: "float64"}}},
+	}}
+
+	mod.harvestExamples(examples)
+
+	assert.DeepEqual(t, mod.Map["function"][0].Examples, []Example{
+		{Mapping: `root = str_contains("seafood", "foo")`, Results: [][2]string{{"{}", "true"}}},
+		{Mapping: `root = str_contains("", "")`, Results: [][2]string{{"{}", "true"}}},
+	})
+	// not a single call, or printing several values
+	assert.Equal(t, len(mod.Map["function"][1].Examples), 0)
+	assert.DeepEqual(t, mod.Map["function"][2].Examples, []Example{
+		{Mapping: `root = str_index("chicken", "dmr")`, Results: [][2]string{{"{}", "-1"}}},
+	})
+	// args that aren't literals, or a result spanning lines
+	assert.Equal(t, len(mod.Map["function"][3].Examples), 0)
+	// not 1e+06, which bloblang prints as 1000000
+	assert.DeepEqual(t, mod.Map["function"][4].Examples, []Example{
+		{Mapping: `root = str_abs(-15)`, Results: [][2]string{{"{}", "15"}}},
+	})
+}
+
+func Test_setDefaults(t *testing.T) {
+	formatFloat := func() *Function {
+		return &Function{
+			Name: "FormatFloat",
+			Args: []Arg{
+				{Name: "f", Type: "float64"},
+				{Name: "fmt", Type: "byte"},
+				{Name: "prec", Type: "int"},
+				{Name: "bitSize", Type: "int"},
+			},
+			Return: []Arg{{Type: "string"}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		defaults map[string]any
+		want     []string
+		err      error
+	}{
+		{
+			name:     "trailing",
+			defaults: map[string]any{"fmt": "g", "prec": float64(-1), "bit_size": float64(64)},
+			want:     []string{"", `"g"`, "int64(-1)", "int64(64)"},
+		},
+		{
+			name:     "float",
+			defaults: map[string]any{"f": 1.5, "fmt": float64(101), "prec": float64(2), "bitSize": float64(32)},
+			want:     []string{"float64(1.5)", "int64(101)", "int64(2)", "int64(32)"},
+		},
+		{
+			name:     "not trailing",
+			defaults: map[string]any{"prec": float64(-1)},
+			err:      ErrInvalidConfig,
+		},
+		{
+			name:     "unknown param",
+			defaults: map[string]any{"bitSize": float64(64), "size": float64(64)},
+			err:      ErrInvalidConfig,
+		},
+		{
+			name:     "wrong type",
+			defaults: map[string]any{"bitSize": "64"},
+			err:      ErrInvalidConfig,
+		},
+		{
+			name:     "not a character",
+			defaults: map[string]any{"fmt": "gg", "prec": float64(-1), "bitSize": float64(64)},
+			err:      ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod := &Module{Name: "strconv", Options: Options{Config: &Config{
+				Functions: map[string]FunctionConfig{"strconv.FormatFloat": {Defaults: tt.defaults}},
+			}}}
+
+			f := formatFloat()
+			err := mod.setDefaults(f)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			for i, arg := range f.Args {
+				assert.Equal(t, arg.Default, tt.want[i])
+			}
+		})
+	}
+
+	assert.Equal(t, anyLiteral(map[string]any{"b": []any{float64(1), "x"}, "a": nil}), `map[string]any{"a": nil, "b": []any{int64(1), "x"}}`)
+}
+
+func Test_pluginName(t *testing.T) {
+	tests := []struct {
+		path   string
+		name   string
+		naming string
+		prefix string
+		want   string
+	}{
+		{path: "github.com/hbollon/go-edlib", name: "edlib", naming: NamingLower, want: "levenshteindistance"},
+		{path: "github.com/hbollon/go-edlib", name: "edlib", naming: NamingSnake, want: "levenshtein_distance"},
+		{path: "github.com/hbollon/go-edlib", name: "edlib", naming: NamingModule, want: "edlib_levenshtein_distance"},
+		{path: "github.com/hbollon/go-edlib", name: "edlib", naming: NamingSnake, prefix: "x_", want: "x_levenshtein_distance"},
+		{path: "math/rand", name: "rand", naming: NamingModule, want: "math_rand_levenshtein_distance"},
+		{path: "crypto/rand", name: "rand", naming: NamingModule, want: "crypto_rand_levenshtein_distance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.naming, func(t *testing.T) {
+			mod := &Module{Name: tt.name, Path: tt.path, Options: Options{Naming: tt.naming, Prefix: tt.prefix}}
+			assert.Equal(t, mod.pluginName(Function{Name: "LevenshteinDistance"}), tt.want)
+		})
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+	tests := map[string]string{
+		"EncodeToString": "encode_to_string",
+		"Float64s":       "float64s",
+		"ParseIP":        "parse_ip",
+		"IsNaN":          "is_nan",
+		"ParseIPv4":      "parse_ipv4",
+		"HTMLEscape":     "html_escape",
+		"IDs":            "ids",
+		"Log1p":          "log1p",
+		"GetX":           "get_x",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, snakeCase(input), want)
+		})
+	}
+}
+
+func Test_checkNames(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(path.Join(dir, "bytes.go"), []byte(`package bloblang
+// RegisterBytes registers the functions of bytes with env
+func RegisterBytes(env *bloblang.Environment) error {
+	err = env.RegisterFunctionV2("contains", objectContainsSpec,
+`), 0o644))
+
+	str := []Arg{{Name: "s", Type: "string"}}
+	strings := func(naming string, funcs ...string) *Module {
+		mod := &Module{Name: "strings", Path: "strings", Options: Options{Naming: naming}}
+		mod.Map = map[string][]*Function{}
+		for _, name := range funcs {
+			mod.Map["function"] = append(mod.Map["function"], &Function{Name: name, Args: str})
+		}
+		return mod
+	}
+
+	assert.NilError(t, strings(NamingLower, "ToUpper", "TrimSpace").checkNames(dir))
+	// taken by bytes.go
+	assert.ErrorIs(t, strings(NamingLower, "ToUpper", "Contains").checkNames(dir), ErrNameCollision)
+	assert.NilError(t, strings(NamingModule, "ToUpper", "Contains").checkNames(dir))
+	// the same name twice
+	assert.ErrorIs(t, strings(NamingLower, "ToUpper", "Toupper").checkNames(dir), ErrNameCollision)
+	assert.NilError(t, strings(NamingSnake, "ToUpper", "Toupper").checkNames(dir))
+
+	// another module with the same package name
+	bytes := &Module{Name: "bytes", Path: "example.com/x/bytes", Options: Options{Naming: NamingModule}}
+	assert.ErrorIs(t, bytes.checkNames(dir), ErrNameCollision)
+
+	// math/rand and crypto/rand side by side
+	assert.NilError(t, os.WriteFile(path.Join(dir, "math_rand.go"), []byte(`package bloblang
+// RegisterMathRand registers the functions of math/rand with env
+func RegisterMathRand(env *bloblang.Environment) error {
+	err = env.RegisterFunctionV2("math_rand_int", objectIntSpec,
+	err = env.RegisterFunctionV2("int", objectIntSpec,
+`), 0o644))
+
+	rand := func(naming string) *Module {
+		mod := &Module{Name: "rand", Path: "crypto/rand", Options: Options{Naming: naming}}
+		mod.Map = map[string][]*Function{"function": {{Name: "Int", Args: str}}}
+		return mod
+	}
+	assert.NilError(t, rand(NamingModule).checkNames(dir))
+	assert.ErrorIs(t, rand(NamingLower).checkNames(dir), ErrNameCollision)
+	assert.Equal(t, rand(NamingModule).fileName(".go"), "crypto_rand.go")
+}
+
+func Test_resolveBuiltins(t *testing.T) {
+	str := []Arg{{Name: "s", Type: "string"}}
+	strings := func(clash string) *Module {
+		mod := &Module{Name: "strings", Path: "strings", Options: Options{
+			Naming:       NamingLower,
+			BuiltinClash: clash,
+			Config: &Config{Functions: map[string]FunctionConfig{
+				"strings.Count": {Alias: "substr_count"},
+			}},
+		}}
+		mod.Map = map[string][]*Function{"function": {
+			{Name: "Count", Args: str},
+			{Name: "Now", Args: str},
+			{Name: "ToUpper", Args: str},
+		}}
+		return mod
+	}
+
+	names := func(mod *Module) []string {
+		names := []string{}
+		for _, f := range mod.Map["function"] {
+			names = append(names, mod.pluginName(*f))
+		}
+		return names
+	}
+
+	mod := strings(BuiltinClashSkip)
+	mod.resolveBuiltins()
+	assert.DeepEqual(t, names(mod), []string{"toupper"})
+
+	mod = strings(BuiltinClashPrefix)
+	mod.resolveBuiltins()
+	assert.DeepEqual(t, names(mod), []string{"strings_count", "strings_now", "toupper"})
+
+	mod = strings(BuiltinClashPrefix)
+	mod.Options.Naming = NamingModule
+	mod.Options.Builtins = []string{"strings_count"}
+	mod.resolveBuiltins()
+	assert.Equal(t, mod.qualifiedName(*mod.Map["function"][0]), "strings_count")
+
+	mod = strings(BuiltinClashAlias)
+	mod.resolveBuiltins()
+	assert.DeepEqual(t, names(mod), []string{"substr_count", "toupper"})
+
+	mod = strings(BuiltinClashSkip)
+	mod.Options.Builtins = []string{"toupper"}
+	mod.resolveBuiltins()
+	assert.DeepEqual(t, names(mod), []string{"count", "now"})
+}
+
+func Test_LoadBuiltins(t *testing.T) {
+	dir := t.TempDir()
+
+	dump := path.Join(dir, "functions.json")
+	assert.NilError(t, os.WriteFile(dump, []byte(`{"bloblang-functions":["count","now"]}`), 0o644))
+
+	builtins, err := LoadBuiltins(dump)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, builtins, []string{"count", "now"})
+
+	methods := path.Join(dir, "methods.json")
+	assert.NilError(t, os.WriteFile(methods, []byte(`{"bloblang-methods":["abs"]}`), 0o644))
+
+	_, err = LoadBuiltins(methods)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func Test_applyOverrides(t *testing.T) {
+	mod := &Module{Name: "math", Options: Options{Config: &Config{Functions: map[string]FunctionConfig{
+		"math.Pow":  {Name: "power", Description: "Raises base to exp.", Params: map[string]string{"x": "base", "y": "exp"}},
+		"math.Sqrt": {Skip: true},
+		"math.Exp":  {Params: map[string]string{"n": "power"}},
+	}}}}
+
+	f := &Function{
+		Name:        "Pow",
+		Description: "Pow returns x**y, the base-x exponential of y.",
+		Args:        []Arg{{Name: "x", Type: "float64"}, {Name: "y", Type: "float64"}},
+	}
+
+	skip, err := mod.applyOverrides(f)
+	assert.NilError(t, err)
+	assert.Equal(t, skip, false)
+	assert.Equal(t, mod.pluginName(*f), "power")
+	assert.Equal(t, f.Description, "Raises base to exp.")
+	assert.Equal(t, paramName(f.Args[0]), "base")
+	assert.Equal(t, paramName(f.Args[1]), "exp")
+
+	skip, err = mod.applyOverrides(&Function{Name: "Sqrt", Args: []Arg{{Name: "x", Type: "float64"}}})
+	assert.NilError(t, err)
+	assert.Equal(t, skip, true)
+
+	_, err = mod.applyOverrides(&Function{Name: "Exp", Args: []Arg{{Name: "x", Type: "float64"}}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	f = &Function{Name: "Abs", Args: []Arg{{Name: "x", Type: "float64"}}}
+	skip, err = mod.applyOverrides(f)
+	assert.NilError(t, err)
+	assert.Equal(t, skip, false)
+	assert.Equal(t, mod.pluginName(*f), "abs")
+}
+
+func Test_filterReason(t *testing.T) {
+	config := &Config{
+		Include: []Filter{{Name: "Trim*"}, {Name: "/^(Has|Cut)/"}, {Doc: "*Unicode*"}},
+		Exclude: []Filter{{Name: "strings.*Func"}, {Signature: "*Suffix*"}},
+	}
+	assert.NilError(t, config.validate())
+
+	mod := &Module{Name: "strings", Options: Options{Config: config}}
+
+	tests := []struct {
+		f    Function
+		want string
+	}{
+		{f: Function{Name: "TrimSpace", Signature: "func TrimSpace(s string) string"}},
+		{f: Function{Name: "HasPrefix", Signature: "func HasPrefix(s, prefix string) bool"}},
+		{f: Function{Name: "ToUpper", Signature: "func ToUpper(s string) string", Description: "ToUpper returns s with all\nUnicode letters mapped to their upper case."}},
+		{f: Function{Name: "Repeat", Signature: "func Repeat(s string, count int) string"}, want: "it matches no include filter"},
+		{f: Function{Name: "TrimFunc", Signature: "func TrimFunc(s string, f func(rune) bool) string"}, want: "it matches the exclude filter name strings.*Func"},
+		{f: Function{Name: "HasSuffix", Signature: "func HasSuffix(s, suffix string) bool"}, want: "it matches the exclude filter signature *Suffix*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.f.Name, func(t *testing.T) {
+			assert.Equal(t, mod.filterReason(&tt.f), tt.want)
+		})
+	}
+
+	assert.ErrorIs(t, (&Config{Exclude: []Filter{{}}}).validate(), ErrInvalidConfig)
+	assert.ErrorIs(t, (&Config{Include: []Filter{{Name: "/(/"}}}).validate(), ErrInvalidConfig)
+}
+
+func Test_classifyEffects(t *testing.T) {
+	src := `package files
+
+import (
+	"os"
+	sys "syscall"
+	"strings"
+)
+
+func Upper(s string) string { return strings.ToUpper(s) }
+
+func Read(name string) ([]byte, error) { return os.ReadFile(name) }
+
+func Load(name string) string {
+	b, _ := Read(name)
+	return Upper(string(b))
+}
+
+func Wipe(name string) error {
+	return retry(func() error { return sys.Unlink(name) })
+}
+
+func Clean(name string) error { return Wipe(name) }
+
+func retry(f func() error) error { return f() }
+
+func IsNotDir(err error) bool { return err == sys.ENOTDIR }
+
+func Join(parts []string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	return Upper(b.String())
+}
+
+func Title(parts []string) string { return Upper(Join(parts)) }
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "files.go", src, 0)
+	assert.NilError(t, err)
+
+	effects := buildCallGraph([]*ast.File{file}).classify("example.com/files")
+
+	tests := map[string]effect{
+		"Upper": {Effects: EffectsPure},
+		"Read":  {Effects: EffectsImpure, Cause: "it calls os.ReadFile"},
+		"Load":  {Effects: EffectsImpure, Cause: "it calls os.ReadFile"},
+		"Wipe":  {Effects: EffectsDangerous, Cause: "it calls syscall.Unlink"},
+		"Clean": {Effects: EffectsDangerous, Cause: "it calls syscall.Unlink"},
+		// a constant isn't a call
+		"IsNotDir": {Effects: EffectsPure},
+		"Join":     {Effects: EffectsUnknown, Cause: "it calls the method WriteString"},
+		"Title":    {Effects: EffectsUnknown, Cause: "it calls the method WriteString"},
+		"retry":    {Effects: EffectsPure},
+	}
+	for name, want := range tests {
+		assert.Equal(t, effects[name], want, name)
+	}
+
+	assert.Equal(t, ruleEffects("os.RemoveAll"), EffectsDangerous)
+	assert.Equal(t, ruleEffects("os.Getenv"), EffectsImpure)
+	assert.Equal(t, ruleEffects("strings.ToUpper"), "")
+}
+
+func Test_setEffects(t *testing.T) {
+	mod := &Module{
+		Name: "os",
+		Path: "os",
+		Options: Options{Config: &Config{Functions: map[string]FunctionConfig{
+			"os.Getenv": {Effects: EffectsPure},
+		}}},
+		effects: map[string]effect{
+			"ReadFile": {Effects: EffectsImpure, Cause: "os.ReadFile is denylisted"},
+		},
+	}
+
+	for name, want := range map[string]string{
+		"ReadFile": EffectsImpure,
+		"Remove":   EffectsDangerous,
+		"Getenv":   EffectsPure,
+		"IsExist":  EffectsUnknown,
+	} {
+		f := &Function{Name: name}
+		assert.Equal(t, mod.setEffects(f), "")
+		assert.Equal(t, f.Effects, want, name)
+	}
+
+	mod.Options.Safe = true
+	assert.Equal(t, mod.setEffects(&Function{Name: "Remove"}), "it is dangerous, os.Remove is denylisted")
+	assert.Equal(t, mod.setEffects(&Function{Name: "ReadFile"}), "")
+}
+
+func Test_guard(t *testing.T) {
+	mod := &Module{
+		Name: "strings",
+		Options: Options{MaxArrayElements: 100, Config: &Config{Functions: map[string]FunctionConfig{
+			"strings.Repeat": {MaxStringBytes: 1024, Timeout: "50ms"},
+			"strings.Fields": {MaxArrayElements: 10},
+		}}},
+	}
+
+	assert.Equal(t, mod.guard(Function{Name: "Repeat"}), "mod2blob.Limits{MaxStringBytes: 1024, MaxArrayElements: 100, Timeout: 50000000}")
+	assert.Equal(t, mod.guard(Function{Name: "Fields"}), "mod2blob.Limits{MaxArrayElements: 10}")
+	assert.Equal(t, mod.guard(Function{Name: "ToUpper"}), "mod2blob.Limits{MaxArrayElements: 100}")
+
+	repeat := Function{Name: "Repeat", Args: []Arg{{Name: "s", Type: "string"}, {Name: "count", Type: "int"}}}
+	assert.Equal(t, mod.guard(repeat), "mod2blob.Limits{MaxStringBytes: 1024, MaxArrayElements: 100, Timeout: 50000000, Counts: []int{1}}")
+
+	mod.Options.MaxArrayElements = 0
+	assert.Equal(t, mod.guard(Function{Name: "ToUpper"}), "")
+	assert.Equal(t, mod.guard(Function{Name: "Sleep", Args: []Arg{{Name: "n", Type: "int"}}}), "")
+
+	assert.ErrorIs(t, (&Options{Timeout: -1}).validate(), ErrInvalidOption)
+}
+
+func Test_memoize(t *testing.T) {
+	mod := &Module{
+		Name: "strings",
+		Options: Options{Memoize: 100, Config: &Config{Functions: map[string]FunctionConfig{
+			"strings.Fields": {Memoize: 10},
+			"os.Getenv":      {Memoize: 10},
+		}}},
+	}
+
+	assert.Equal(t, mod.memoize(Function{Name: "ToUpper", Effects: EffectsPure}), 100)
+	assert.Equal(t, mod.memoize(Function{Name: "Fields", Effects: EffectsPure}), 10)
+	assert.Equal(t, mod.memoize(Function{Name: "Getenv", Effects: EffectsImpure}), 0)
+
+	mod.Options.Memoize = 0
+	assert.Equal(t, mod.memoize(Function{Name: "ToUpper", Effects: EffectsPure}), 0)
+	assert.Equal(t, mod.memoize(Function{Name: "Fields", Effects: EffectsPure}), 10)
+}
+
+func Test_fold(t *testing.T) {
+	mod := &Module{Name: "math", Path: "math", Options: Options{Fold: true}}
+
+	assert.Equal(t, mod.fold(Function{Name: "Sqrt", Effects: EffectsPure}), true)
+	assert.Equal(t, mod.fold(Function{Name: "Sqrt", Effects: EffectsImpure}), false)
+
+	mod.Options.Fold = false
+	assert.Equal(t, mod.fold(Function{Name: "Sqrt", Effects: EffectsPure}), false)
+
+	// pure by analysis alone isn't enough
+	mod = &Module{Name: "utf8", Path: "unicode/utf8", Options: Options{Fold: true}}
+	assert.Equal(t, mod.fold(Function{Name: "EncodeRune", Effects: EffectsPure}), false)
+	assert.Equal(t, mod.fold(Function{Name: "RuneLen", Effects: EffectsPure}), true)
+
+	mod = &Module{Name: "edlib", Path: "github.com/hbollon/go-edlib", Options: Options{Fold: true, Config: &Config{Functions: map[string]FunctionConfig{
+		"edlib.LevenshteinDistance": {Effects: EffectsPure},
+		"edlib.StringsSimilarity":   {Effects: EffectsImpure},
+	}}}}
+	assert.Equal(t, mod.fold(Function{Name: "LevenshteinDistance", Effects: EffectsUnknown}), true)
+	assert.Equal(t, mod.fold(Function{Name: "StringsSimilarity", Effects: EffectsPure}), false)
+	assert.Equal(t, mod.fold(Function{Name: "Jaro", Effects: EffectsPure}), false)
+}
+
+func Test_header(t *testing.T) {
+	body := []byte("package bloblang\n")
+
+	got := string(generatedHeader("//", "Code generated by mod2blob. DO NOT EDIT.", []string{"mod2blob: v1.0.0"}, body))
+	assert.Equal(t, got, `// Code generated by mod2blob. DO NOT EDIT.
+//
+// mod2blob: v1.0.0
+// sha256:   fdd746ca9a642921d12ff8a5671ea4ec3d63f24d90cb486a0e09b464b2881870
+
+package bloblang
+`)
+
+	mod := &Module{Name: "strconv", Path: "strconv", Version: "go1.22.2", Options: Options{Prefix: "s", Timeout: time.Second}}
+	assert.NilError(t, mod.Options.validate())
+
+	lines := strings.Split(string(mod.header("#", []byte("input: {}\n"))), "\n")
+	assert.Equal(t, lines[0], "# Code generated by mod2blob from strconv. DO NOT EDIT.")
+	assert.Equal(t, lines[2], "# module:   strconv")
+	assert.Equal(t, lines[3], "# version:  go1.22.2")
+	assert.Assert(t, strings.HasPrefix(lines[4], "# mod2blob: "))
+	assert.Equal(t, lines[5], "# options:  -prefix=s -naming=lower -builtin-clash=skip -non-finite=error")
+	assert.Assert(t, strings.HasSuffix(lines[len(lines)-5], "-timeout=1s"))
+	assert.Assert(t, strings.HasPrefix(lines[len(lines)-4], "# sha256:   "))
+	assert.Equal(t, strings.Join(lines[len(lines)-3:], "\n"), "\ninput: {}\n")
+
+	for _, l := range lines {
+		assert.Assert(t, len(l) <= headerWidth+4, l)
+	}
+}
+
+func Test_RegisterName(t *testing.T) {
+	tests := map[string]string{
+		"math":                        "RegisterMath",
+		"encoding/hex":                "RegisterEncodingHex",
+		"math/rand":                   "RegisterMathRand",
+		"crypto/rand":                 "RegisterCryptoRand",
+		"github.com/hbollon/go-edlib": "RegisterEdlib",
+		"github.com/spatial-go/geoos": "RegisterGeoos",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			mod := &Module{Name: path.Base(input), Path: input}
+			if input == "github.com/hbollon/go-edlib" {
+				mod.Name = "edlib"
+			}
+			assert.Equal(t, mod.RegisterName(), want)
+		})
+	}
+}
+
+func Test_getModuleName(t *testing.T) {
+	tests := map[string]string{
+		"math":         "math",
+		"encoding/hex": "hex",
+		"net/netip":    "netip",
+		"math/rand":    "rand",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			name, err := getModuleName(input)
+			assert.NilError(t, err)
+			assert.Equal(t, name, want)
+		})
+	}
+}
+
+func Test_parseConst(t *testing.T) {
+	tests := []struct {
+		line     string
+		prev     Constant
+		expected Constant
+		ok       bool
+	}{
+		{
+			line:     "\tJanuary Month = 1 + iota",
+			expected: Constant{Name: "January", Type: "Month", Value: "1 + iota"},
+			ok:       true,
+		},
+		{
+			line:     "\tFebruary // the second",
+			prev:     Constant{Name: "January", Type: "Month", Value: "1 + iota"},
+			expected: Constant{Name: "February", Type: "Month"},
+			ok:       true,
+		},
+		{
+			line:     "\tMicrosecond          = 1000 * Nanosecond",
+			prev:     Constant{Name: "Nanosecond", Type: "Duration", Value: "1"},
+			expected: Constant{Name: "Microsecond", Type: "Duration", Value: "1000 * Nanosecond"},
+			ok:       true,
+		},
+		{
+			line:     "\tMaxInt8 = 1<<7 - 1",
+			prev:     Constant{Name: "E", Value: "2.718"},
+			expected: Constant{Name: "MaxInt8", Value: "1<<7 - 1"},
+			ok:       true,
+		},
+		{
+			line: "\t// Deprecated: use Now",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			actual, ok := parseConst(tt.line, tt.prev)
+			assert.Equal(t, ok, tt.ok)
+			assert.DeepEqual(t, actual, tt.expected)
+		})
+	}
+}
+
+func Test_parseDoc(t *testing.T) {
+	mod := &Module{
+		Name: "math",
+		raw: []byte(strings.Join([]string{
+			"func Abs(x float64) float64",
+			"    Abs returns the absolute value of x.",
+			"",
+			"func Cbrt(x float64) float64",
+			"    Cbrt returns the cube root of x.",
+			"",
+			"    Special cases are:",
+			"",
+			"        Cbrt(±0) = ±0",
+			"",
+			"func Ceil(x float64) float64",
+			"    Ceil returns the least integer value greater than or equal to x.",
+			"",
+			"func Reverse[S ~[]E, E any](s S)",
+			"    Reverse reverses the elements of the slice in place.",
+			"",
+			"func (d Duration) Hours() float64",
+			"",
+		}, "\n")),
+	}
+	assert.NilError(t, mod.parseDoc())
+	assert.DeepEqual(t, mod.Skipped, []Skip{{Function: "Reverse", Reason: "it is generic"}})
+
+	names := []string{}
+	for _, f := range mod.Functions {
+		names = append(names, f.Name)
+	}
+	// Cbrt starts on an odd line, Ceil after a doc comment of five lines
+	assert.DeepEqual(t, names, []string{"Abs", "Cbrt", "Ceil"})
+	assert.Equal(t, mod.Functions[1].Description, "Cbrt returns the cube root of x.\n\nSpecial cases are:\n\n    Cbrt(±0) = ±0")
+}
+
+func Test_enums(t *testing.T) {
+	mod := &Module{
+		Name: "fixture",
+		// in the order go doc prints them, with the standalone alias
+		// before the type of its value
+		raw: []byte(strings.Join([]string{
+			"CONSTANTS",
+			"",
+			"const Crimson = Red",
+			"    Crimson is Red.",
+			"",
+			"TYPES",
+			"",
+			"type Color int",
+			"const (",
+			"\tRed Color = iota",
+			"\tGreen",
+			"\tScarlet = Red",
+			"\tmaxColor",
+			")",
+			"type Size int64",
+			"const (",
+			"\tByte Size = 1",
+			"\tKB        = 1024 * Byte",
+			")",
+			"type Flag uint",
+			"const (",
+			"\tFlagA Flag = 1 << iota",
+			"\tFlagB",
+			")",
+			"type Op string",
+			"const OpAdd Op = \"add\"",
+			"type Point struct {",
+			"const Pi = 3.14",
+		}, "\n")),
+	}
+	assert.NilError(t, mod.parseDoc())
+
+	assert.DeepEqual(t, mod.Enums, []Enum{
+		{Type: "fixture.Color", Underlying: "int", Names: []string{"Red", "Green", "Scarlet", "Crimson"}},
+		{Type: "fixture.Op", Underlying: "string", Names: []string{"OpAdd"}},
+	})
+	assert.Equal(t, mod.Constants[slices.IndexFunc(mod.Constants, func(c Constant) bool { return c.Name == "KB" })].Type, "Size")
+
+	assert.Equal(t, mod.convertArg(Arg{Name: "c", Type: "Color"}),
+		"ca, err := mod2blob.EnumInt(\"c\", c, fixtureColorConsts)\nif err != nil {\nreturn nil, err\n}")
+	assert.Equal(t, mod.paramType("Op"), "Any")
+	assert.Equal(t, mod.enumVar(mod.enumFor("time.Month")), "fixtureTimeMonthConsts")
+
+	mod.Options.EnumResults = EnumResultsName
+	mod.Options.BigUint = BigUintString
+	assert.Equal(t, mod.convertResult("fixture.Next", Arg{Type: "Color"}, "r"), `mod2blob.EnumName("fixture.Next", r, fixtureColorConsts, "", "string")`)
+
+	mod.Options.EnumResults = EnumResultsNumber
+	assert.Equal(t, mod.enumResult("fixture.Next", "Color", "r"), "")
+}
+
+func Test_returnResults(t *testing.T) {
+	mod := &Module{
+		Name: "math",
+		Options: Options{
+			NonFinite: NonFiniteError,
+			BigUint:   BigUintNumber,
+			Results:   ResultsNamed,
+			Config: &Config{
+				Functions: map[string]FunctionConfig{"math.Frexp": {Results: ResultsArray}},
+			},
+		},
+	}
+
+	tests := []struct {
+		input    Function
+		expected string
+	}{
+		{
+			input:    Function{Name: "Atoi", Return: []Arg{{Type: "int"}, {Type: "error"}}},
+			expected: "r0, err := math.Atoi(sa)\nif err != nil {\nreturn nil, err\n}\nreturn r0, nil",
+		},
+		{
+			input:    Function{Name: "Check", Return: []Arg{{Name: "err", Type: "error"}}},
+			expected: "if err := math.Check(sa); err != nil {\nreturn nil, err\n}\nreturn nil, nil",
+		},
+		{
+			input:    Function{Name: "Len", Return: []Arg{{Type: "int"}}},
+			expected: "return math.Len(sa), nil",
+		},
+		{
+			input:    Function{Name: "Split", Return: []Arg{{Name: "dir", Type: "string"}, {Type: "int"}}},
+			expected: "r0, r1 := math.Split(sa)\nout := map[string]any{}\nout[\"dir\"] = r0\nout[\"r1\"] = r1\nreturn out, nil",
+		},
+		{
+			input: Function{Name: "Frexp", Return: []Arg{{Type: "float64"}, {Type: "int"}}},
+			expected: "r0, r1 := math.Frexp(sa)\nvar err error\nout := make([]any, 2)\n" +
+				"if out[0], err = mod2blob.Float(\"math.Frexp\", r0, \"error\"); err != nil {\nreturn nil, err\n}\n" +
+				"out[1] = r1\nreturn out, nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input.Name, func(t *testing.T) {
+			call := "math." + tt.input.Name + "(sa)"
+			assert.Equal(t, mod.returnResults("math."+tt.input.Name, tt.input, call), tt.expected)
+		})
+	}
+
+	results := []Arg{{Name: "frac", Type: "float64"}, {Type: "int"}}
+	assert.DeepEqual(t, resultNames(results, ResultsNamed), []string{"frac", "r1"})
+	assert.DeepEqual(t, resultNames(results, ResultsIndexed), []string{"r0", "r1"})
+
+	config := &Config{Functions: map[string]FunctionConfig{"Frexp": {Results: "tuple"}}}
+	assert.Equal(t, config.validate(), ErrInvalidConfig)
+}
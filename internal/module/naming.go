@@ -0,0 +1,192 @@
+package module
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// pluginNamePattern is what bloblang accepts as a function name
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// registeredName finds the names registered by generated code
+var registeredName = regexp.MustCompile(`RegisterFunctionV2\("([a-z0-9_]+)"`)
+
+// generatedFrom finds the module a generated file was generated from
+// in the comment of its Register func
+var generatedFrom = regexp.MustCompile(`registers the functions of (\S+) with env`)
+
+// snakeCase converts a Go identifier to snake_case, so bitSize becomes
+// bit_size, srcURL src_url, IsNaN is_nan and ParseIPv4 parse_ipv4
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && startsWord(runes, i) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// startsWord reports whether the upper case rune at i starts a word.
+// Acronyms are kept whole, along with a lower case letter inside or
+// after them such as the a of NaN and the v of IPv4, while the last
+// capital of HTMLEscape starts Escape.
+func startsWord(runes []rune, i int) bool {
+	lower := func(j int) bool { return j < len(runes) && unicode.IsLower(runes[j]) }
+	upper := func(j int) bool { return j >= 0 && unicode.IsUpper(runes[j]) }
+
+	prev := runes[i-1]
+	switch {
+	case unicode.IsDigit(prev):
+		return true
+	case unicode.IsLower(prev):
+		// the closing N of NaN
+		return !upper(i-2) || lower(i+1)
+	default:
+		// the P of IPv4 and IDs is followed by a single lower case
+		// letter, the E of HTMLEscape by a word
+		return lower(i+1) && lower(i+2)
+	}
+}
+
+// qualifier returns the module part of NamingModule names and of the
+// generated file and Register func: the import path of runtime
+// packages, so math/rand and crypto/rand differ, and the package name
+// of anything else
+func (mod *Module) qualifier() string {
+	q := mod.Name
+	if strings.Count(mod.Path, "/") < 2 {
+		q = mod.Path
+	}
+	return strings.ReplaceAll(snakeCase(q), "/", "_")
+}
+
+// fileName returns the name of the generated file of the module with
+// ext, such as math_rand.go or math_rand_examples.yaml
+func (mod *Module) fileName(ext string) string {
+	return mod.qualifier() + ext
+}
+
+// RegisterName returns the name of the generated func that registers
+// the module with a bloblang environment, the qualifier in camel case
+// after Register, such as RegisterMath or RegisterMathRand
+func (mod *Module) RegisterName() string {
+	var b strings.Builder
+
+	b.WriteString("Register")
+	for _, part := range strings.Split(mod.qualifier(), "_") {
+		runes := []rune(part)
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// pluginName returns the name f is registered under in bloblang,
+// the prefix followed by the name the naming strategy gives it,
+// unless it had to be renamed
+func (mod *Module) pluginName(f Function) string {
+	if f.BloblangName != "" {
+		return f.BloblangName
+	}
+	return mod.Options.Prefix + mod.baseName(f)
+}
+
+// baseName returns the name the naming strategy gives f
+func (mod *Module) baseName(f Function) string {
+	switch mod.Options.Naming {
+	case NamingSnake:
+		return snakeCase(f.Name)
+	case NamingModule:
+		return mod.qualifier() + "_" + snakeCase(f.Name)
+	}
+	return strings.ToLower(f.Name)
+}
+
+// checkNames reports generated functions whose names bloblang would
+// reject, that share a name, or that take a name already registered
+// by another module generated into outputDir, all of which end up in
+// the same registry. Packages generated into other directories aren't
+// seen, though they may be registered into the same environment.
+func (mod *Module) checkNames(outputDir string) error {
+	fileName := mod.fileName(".go")
+
+	src, err := os.ReadFile(path.Join(outputDir, fileName))
+	if err == nil {
+		if m := generatedFrom.FindSubmatch(src); m != nil && string(m[1]) != mod.Path {
+			log.Printf("%s: %s was generated from %s, generate %s into another directory\n", mod.GetName(), fileName, m[1], mod.Path)
+			return ErrNameCollision
+		}
+	}
+
+	taken, err := generatedNames(outputDir, fileName)
+	if err != nil {
+		return err
+	}
+
+	ok := true
+	seen := map[string]string{}
+
+	for _, f := range mod.Map["function"] {
+		name := mod.pluginName(*f)
+
+		if !pluginNamePattern.MatchString(name) {
+			log.Printf("%s: %s is registered as %q, which is not a valid bloblang name\n", mod.GetName(), f.Name, name)
+			ok = false
+		}
+
+		if other, found := seen[name]; found {
+			log.Printf("%s: %s and %s are both registered as %q\n", mod.GetName(), other, f.Name, name)
+			ok = false
+		}
+		seen[name] = f.Name
+
+		if file, found := taken[name]; found {
+			log.Printf("%s: %s is registered as %q, which %s already registers\n", mod.GetName(), f.Name, name, file)
+			ok = false
+		}
+	}
+
+	if !ok {
+		log.Println("use -prefix or -naming to tell the functions apart")
+		return ErrNameCollision
+	}
+	return nil
+}
+
+// generatedNames returns the functions registered by the generated
+// files in outputDir other than exclude, keyed by name
+func generatedNames(outputDir string, exclude string) (map[string]string, error) {
+	files, err := filepath.Glob(path.Join(outputDir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, file := range files {
+		base := filepath.Base(file)
+		if base == exclude || base == helpersFileName {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range registeredName.FindAllSubmatch(src, -1) {
+			names[string(m[1])] = base
+		}
+	}
+	return names, nil
+}
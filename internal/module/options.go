@@ -0,0 +1,95 @@
+package module
+
+import (
+	"log"
+)
+
+// validate fills in defaults for unset options and rejects
+// values the templates do not know how to render
+func (o *Options) validate() error {
+	switch o.NonFinite {
+	case "":
+		o.NonFinite = NonFiniteError
+	case NonFiniteError, NonFiniteNull, NonFiniteString:
+	default:
+		log.Printf("non-finite policy must be one of error, null or string, got %q\n", o.NonFinite)
+		return ErrInvalidOption
+	}
+
+	switch o.BigUint {
+	case "":
+		o.BigUint = BigUintNumber
+	case BigUintNumber, BigUintString:
+	default:
+		log.Printf("big uint strategy must be one of number or string, got %q\n", o.BigUint)
+		return ErrInvalidOption
+	}
+
+	switch {
+	case o.MaxElements == 0:
+		o.MaxElements = DefaultMaxElements
+	case o.MaxElements < 0:
+		log.Printf("max elements must be positive, got %d\n", o.MaxElements)
+		return ErrInvalidOption
+	}
+
+	switch {
+	case o.ChanWait == 0:
+		o.ChanWait = DefaultChanWait
+	case o.ChanWait < 0:
+		log.Printf("chan wait must be positive, got %s\n", o.ChanWait)
+		return ErrInvalidOption
+	}
+
+	switch o.IterPairs {
+	case "":
+		o.IterPairs = IterPairsArray
+	case IterPairsArray, IterPairsObject:
+	default:
+		log.Printf("iterator pairs must be one of array or object, got %q\n", o.IterPairs)
+		return ErrInvalidOption
+	}
+
+	switch o.EnumResults {
+	case "":
+		o.EnumResults = EnumResultsName
+	case EnumResultsName, EnumResultsNumber:
+	default:
+		log.Printf("enum results must be one of name or number, got %q\n", o.EnumResults)
+		return ErrInvalidOption
+	}
+
+	switch o.Results {
+	case "":
+		o.Results = ResultsNamed
+	case ResultsArray, ResultsNamed, ResultsIndexed:
+	default:
+		log.Printf("results must be one of array, named or indexed, got %q\n", o.Results)
+		return ErrInvalidOption
+	}
+
+	switch o.Naming {
+	case "":
+		o.Naming = NamingLower
+	case NamingLower, NamingSnake, NamingModule:
+	default:
+		log.Printf("naming must be one of lower, snake or module, got %q\n", o.Naming)
+		return ErrInvalidOption
+	}
+
+	switch o.BuiltinClash {
+	case "":
+		o.BuiltinClash = BuiltinClashSkip
+	case BuiltinClashSkip, BuiltinClashPrefix, BuiltinClashAlias:
+	default:
+		log.Printf("builtin clash must be one of skip, prefix or alias, got %q\n", o.BuiltinClash)
+		return ErrInvalidOption
+	}
+
+	if o.MaxStringBytes < 0 || o.MaxArrayElements < 0 || o.Timeout < 0 || o.Memoize < 0 {
+		log.Printf("max string bytes, max array elements, timeout and memoize must not be negative\n")
+		return ErrInvalidOption
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package module
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// setOutput works out where a function that writes into one of its
+// args leaves its output, and reports false for a function without
+// results that has nothing to return.
+//
+// A leading dst slice of a function with results, such as
+// hex.Encode(dst, src []byte) int, is allocated by the plugin and
+// sized from the src that follows it. The dst of an Append function
+// such as hex.AppendEncode is the prefix the result starts with, so
+// it stays a param. When the only results besides the written arg
+// are a count and an error, the written arg is returned instead, so
+// hex.Encode returns the encoded bytes.
+//
+// Without a dst, the arg written into is returned only when it
+// provably is: a function without results writes into its arg or
+// does nothing, so sort.Float64s returns the sorted copy, while a
+// function returning just an error must name the arg like a buffer.
+// Otherwise the result stays null or the error.
+func (mod *Module) setOutput(f *Function) bool {
+	if isVariadic(*f) {
+		return len(f.Return) > 0
+	}
+
+	if len(f.Return) > 0 && len(f.Args) > 1 && f.Args[0].Name == "dst" && strings.HasPrefix(f.Args[0].Type, "[]") && !strings.HasPrefix(f.Name, "Append") {
+		for _, src := range f.Args[1:] {
+			if strings.HasPrefix(src.Type, "[]") {
+				f.Dst = f.Args[0].Name
+				f.DstSize = mod.dstSize(f.Name, src)
+				break
+			}
+		}
+	}
+
+	if f.Dst != "" && writesOnly(f.Return, true) {
+		f.Out = f.Dst
+		return true
+	}
+
+	if !writesOnly(f.Return, false) {
+		return true
+	}
+
+	for _, a := range f.Args {
+		if mod.isWritable(a) && (len(f.Return) == 0 || isBufferName(a.Name)) {
+			f.Out = a.Name
+			return true
+		}
+	}
+
+	// a function without results that writes nothing back
+	return len(f.Return) > 0
+}
+
+// writesOnly reports whether results hold nothing but an error and,
+// if count is set, the number of elements written
+func writesOnly(results []Arg, count bool) bool {
+	if n := len(results); n > 0 && results[n-1].Type == "error" {
+		results = results[:n-1]
+	}
+
+	if count && len(results) == 1 && results[0].Type == "int" {
+		results = results[:0]
+	}
+	return len(results) == 0
+}
+
+// isBufferName reports whether name is the conventional name of an
+// arg a function writes into, such as dst, dest or buf
+func isBufferName(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"dst", "dest", "buf"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWritable reports whether a function can write into arg in a way
+// the caller sees: a slice, or a struct the plugin has an adapter for
+func (mod *Module) isWritable(arg Arg) bool {
+	if strings.HasPrefix(arg.Type, "[]") {
+		return rawConverter(arg.Type) != "" || mod.adapterFor(arg.Type) != nil
+	}
+
+	if strings.HasPrefix(arg.Type, "*") {
+		a := mod.adapterFor(arg.Type)
+		return a != nil && a.ToGo != "" && a.FromGo != ""
+	}
+	return false
+}
+
+// dstSize returns the length of the dst buffer of funcName. Packages
+// like encoding/hex have a companion such as EncodedLen for Encode,
+// anything else gets a buffer as long as src.
+func (mod *Module) dstSize(funcName string, src Arg) string {
+	srcLen := fmt.Sprintf("len(%s)", callArg(src))
+
+	candidates := []string{funcName + "dLen", "Max" + funcName + "dLen", funcName + "Len"}
+
+	for _, f := range mod.Functions {
+		if !slices.Contains(candidates, f.Name) {
+			continue
+		}
+		if len(f.Args) == 1 && f.Args[0].Type == "int" && len(f.Return) == 1 && f.Return[0].Type == "int" {
+			return fmt.Sprintf("%s.%s(%s)", mod.Name, f.Name, srcLen)
+		}
+	}
+	return srcLen
+}
+
+// params returns the args of f that are bloblang params
+func params(f Function) []Arg {
+	args := []Arg{}
+	for _, a := range f.Args {
+		if a.Name != f.Dst {
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+// passArg returns how arg is passed to f. The leading slice of an
+// Append function such as hex.AppendEncode is converted once, when
+// the mapping is parsed, so it is cut to its length: append then
+// copies it instead of writing past its end into the array shared by
+// every call.
+func passArg(f Function, arg Arg) string {
+	expr := callArg(arg)
+	if strings.HasPrefix(f.Name, "Append") && f.Args[0].Name == arg.Name && strings.HasPrefix(arg.Type, "[]") {
+		return fmt.Sprintf("%s[:len(%s):len(%s)]", expr, expr, expr)
+	}
+	return expr
+}
+
+// argByName returns the arg of f called name
+func argByName(f Function, name string) Arg {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return Arg{}
+}
+
+// outConversion returns the call converting the param fetched for
+// arg, which for the arg a function writes into happens on every
+// call, so that each call starts from the mapping's value
+func (mod *Module) outConversion(arg Arg) string {
+	if a := mod.adapterFor(arg.Type); a != nil {
+		return fmt.Sprintf("%s(%s)", a.ToGo, arg.Name)
+	}
+	return fmt.Sprintf("%s(%q, %s)", rawConverter(arg.Type), arg.Name, arg.Name)
+}
+
+// checkOut validates the param of the arg a function writes into
+// when the mapping is parsed
+func (mod *Module) checkOut(arg Arg) string {
+	return fmt.Sprintf("if _, err = %s; err != nil {\nreturn nil, err\n}", mod.outConversion(arg))
+}
+
+// prepareOut returns the statements run before each call of f that
+// allocate its dst buffer or convert a fresh copy of the arg it
+// writes into
+func (mod *Module) prepareOut(f Function) string {
+	if f.Dst != "" {
+		dst := argByName(f, f.Dst)
+		return fmt.Sprintf("%s := make(%s, %s)", callArg(dst), dst.Type, f.DstSize)
+	}
+
+	if f.Out != "" {
+		return checkedConversion(f.Out, mod.outConversion(argByName(f, f.Out)))
+	}
+	return ""
+}
+
+// returnOut returns the statements that call f and return the arg
+// it wrote into, cut to the count it returns, if any
+func (mod *Module) returnOut(qualName string, f Function, call string) string {
+	out := argByName(f, f.Out)
+	expr := callArg(out)
+
+	var stmts string
+
+	switch results := f.Return; {
+	case len(results) == 0:
+		stmts = call
+	case len(results) == 1 && results[0].Type == "error":
+		stmts = fmt.Sprintf("if err := %s; err != nil {\nreturn nil, err\n}", call)
+	case len(results) == 1:
+		stmts = fmt.Sprintf("n := %s", call)
+		expr += "[:n]"
+	default:
+		stmts = fmt.Sprintf("n, err := %s\nif err != nil {\nreturn nil, err\n}", call)
+		expr += "[:n]"
+	}
+
+	if conv := mod.convertResult(qualName, out, expr); conv != "" {
+		return fmt.Sprintf("%s\nreturn %s", stmts, conv)
+	}
+	return fmt.Sprintf("%s\nreturn %s, nil", stmts, expr)
+}
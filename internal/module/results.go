@@ -0,0 +1,106 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resultShape returns how the results of f are combined, taking a
+// shape set for f in the project config over the -results option
+func (mod *Module) resultShape(f Function) string {
+	if fc := mod.functionConfig(f.Name); fc != nil && fc.Results != "" {
+		return fc.Results
+	}
+	return mod.Options.Results
+}
+
+// resultNames returns the keys of the object the results of f are
+// returned as. Unnamed results, and all of them when shape is
+// ResultsIndexed, are named after their position: r0, r1, ...
+func resultNames(results []Arg, shape string) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+		if names[i] == "" || shape == ResultsIndexed {
+			names[i] = fmt.Sprintf("r%d", i)
+		}
+	}
+	return names
+}
+
+// returnResults returns the statements that call f and return its
+// results. A trailing error result fails the mapping when it is set,
+// a single remaining result is returned as it is and several are
+// combined according to resultShape.
+func (mod *Module) returnResults(qualName string, f Function, call string) string {
+	values := f.Return
+	hasErr := len(values) > 0 && values[len(values)-1].Type == "error"
+	if hasErr {
+		values = values[:len(values)-1]
+	}
+
+	if len(values) == 0 {
+		if hasErr {
+			return fmt.Sprintf("if err := %s; err != nil {\nreturn nil, err\n}\nreturn nil, nil", call)
+		}
+		return fmt.Sprintf("return %s, nil", call)
+	}
+
+	if len(values) == 1 && !hasErr {
+		if conv := mod.convertResult(qualName, values[0], call); conv != "" {
+			return "return " + conv
+		}
+		return fmt.Sprintf("return %s, nil", call)
+	}
+
+	vars := make([]string, len(values))
+	for i := range values {
+		vars[i] = fmt.Sprintf("r%d", i)
+	}
+
+	var b strings.Builder
+
+	if hasErr {
+		fmt.Fprintf(&b, "%s, err := %s\nif err != nil {\nreturn nil, err\n}\n", strings.Join(vars, ", "), call)
+	} else {
+		fmt.Fprintf(&b, "%s := %s\n", strings.Join(vars, ", "), call)
+	}
+
+	if len(values) == 1 {
+		if conv := mod.convertResult(qualName, values[0], vars[0]); conv != "" {
+			fmt.Fprintf(&b, "return %s", conv)
+		} else {
+			fmt.Fprintf(&b, "return %s, nil", vars[0])
+		}
+		return b.String()
+	}
+
+	if !hasErr && mod.needsConversion(values) {
+		b.WriteString("var err error\n")
+	}
+
+	shape := mod.resultShape(f)
+	names := resultNames(values, shape)
+
+	if shape == ResultsArray {
+		fmt.Fprintf(&b, "out := make([]any, %d)\n", len(values))
+	} else {
+		b.WriteString("out := map[string]any{}\n")
+	}
+
+	for i, r := range values {
+		key := fmt.Sprintf("out[%q]", names[i])
+		if shape == ResultsArray {
+			key = fmt.Sprintf("out[%d]", i)
+		}
+
+		if conv := mod.convertResult(qualName, r, vars[i]); conv != "" {
+			fmt.Fprintf(&b, "if %s, err = %s; err != nil {\nreturn nil, err\n}\n", key, conv)
+		} else {
+			fmt.Fprintf(&b, "%s = %s\n", key, vars[i])
+		}
+	}
+	b.WriteString("return out, nil")
+
+	return b.String()
+}
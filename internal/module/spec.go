@@ -0,0 +1,87 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseDescription returns the doc comment go doc prints under the
+// declaration at lines[i], with its indent removed. Paragraphs stay
+// separated by blank lines and code blocks keep their own indent.
+func parseDescription(lines []string, i int) string {
+	doc := []string{}
+
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "    ") {
+			break
+		}
+		doc = append(doc, strings.TrimRight(strings.TrimPrefix(line, "    "), " "))
+	}
+	return strings.TrimSpace(strings.Join(doc, "\n"))
+}
+
+// docComment renders a description as a Go comment
+func docComment(description string) string {
+	if description == "" {
+		return ""
+	}
+
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = "//"
+		} else {
+			lines[i] = "// " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paramDescription returns the help text of the param for arg, what
+// go doc can't tell: the constants an enum param takes by name and
+// whether it may be left out. It is empty for other params, as doc
+// comments don't describe params one by one.
+func (mod *Module) paramDescription(arg Arg) string {
+	desc := []string{}
+
+	if e := mod.enumFor(arg.Type); e != nil {
+		desc = append(desc, fmt.Sprintf("Takes the name of a constant (%s) or its value.", strings.Join(e.Names, ", ")))
+	}
+
+	if isOptional(arg) {
+		desc = append(desc, "Omit it or pass null for nil.")
+	}
+	return strings.Join(desc, " ")
+}
+
+// specMeta returns the calls adding the documentation of f to its
+// plugin spec: its description, the module as category, the version
+// of the plugin that added it when the project config gives one, any
+// examples and whether it is impure
+func (mod *Module) specMeta(f Function) string {
+	var b strings.Builder
+
+	if f.Description != "" {
+		fmt.Fprintf(&b, ".\nDescription(%q)", f.Description)
+	}
+
+	fmt.Fprintf(&b, ".\nCategory(%q)", mod.Path)
+
+	if fc := mod.functionConfig(f.Name); fc != nil && fc.Version != "" {
+		fmt.Fprintf(&b, ".\nVersion(%q)", fc.Version)
+	}
+
+	for _, ex := range f.Examples {
+		fmt.Fprintf(&b, ".\nExample(%q, %q", ex.Summary, ex.Mapping)
+		for _, r := range ex.Results {
+			fmt.Fprintf(&b, ", [2]string{%q, %q}", r[0], r[1])
+		}
+		b.WriteString(")")
+	}
+
+	if f.Effects == EffectsImpure || f.Effects == EffectsDangerous {
+		b.WriteString(".\nImpure()")
+	}
+	return b.String()
+}
@@ -1,29 +1,187 @@
 package module
 
+import "time"
+
 type Module struct {
 	raw       []byte
 	Functions []*Function
 	Name      string
 	Path      string
-	Prefix    string
+	// Version is the tag of the module's checkout, or the Go
+	// version for standard library packages, if it could be found
+	Version   string
+	Options   Options
 	Constants []Constant
+	Enums     []Enum
+	// Skipped lists the functions left out and why, in the order
+	// they were found
+	Skipped []Skip
 	// map[method|function][]*Function
 	Map map[string][]*Function
+
+	// effects classifies the functions of the module by name
+	effects map[string]effect
+}
+
+// Options controls how the functions of a module are turned into
+// bloblang plugins.
+type Options struct {
+	// Prefix is prepended to every generated function name
+	Prefix string
+	// NonFinite selects what NaN and ±Inf float results become,
+	// one of NonFiniteError, NonFiniteNull or NonFiniteString
+	NonFinite string
+	// BigUint selects what uint64 results above math.MaxInt64 become,
+	// one of BigUintNumber or BigUintString
+	BigUint string
+	// MaxElements caps the number of elements collected from
+	// iterator and channel results
+	MaxElements int
+	// ChanWait caps how long channel results are received from, as
+	// a channel may never be closed
+	ChanWait time.Duration
+	// IterPairs selects how iter.Seq2 results are collected, one of
+	// IterPairsArray or IterPairsObject
+	IterPairs string
+	// EnumResults selects whether enum results are returned as the
+	// name of their constant, EnumResultsName, or as EnumResultsNumber
+	EnumResults string
+	// Results selects how functions with several results return
+	// them, one of ResultsArray, ResultsNamed or ResultsIndexed
+	Results string
+	// Naming selects how function names are turned into bloblang
+	// names, one of NamingLower, NamingSnake or NamingModule
+	Naming string
+	// Builtins are the names of the bloblang built-in functions,
+	// the catalogue shipped with mod2blob when nil
+	Builtins []string
+	// BuiltinClash selects what happens to functions named like a
+	// built-in, one of BuiltinClashSkip, BuiltinClashPrefix or
+	// BuiltinClashAlias
+	BuiltinClash string
+	// Init adds an init func that registers the module with the
+	// global bloblang environment, panicking when that fails
+	Init bool
+	// MaxStringBytes and MaxArrayElements cap the size of the args
+	// of every call, strings and byte arrays counted in bytes and
+	// nested arrays and objects included, and Timeout caps how long a
+	// call may run. Zero means unlimited.
+	MaxStringBytes   int
+	MaxArrayElements int
+	Timeout          time.Duration
+	// Memoize caches the results of pure functions, keyed by their
+	// args, in an LRU cache of this many entries per function. Zero
+	// disables it.
+	Memoize int
+	// Fold calls the functions known to be pure in the constructor
+	// of their plugin, which bloblang runs once when the mapping is
+	// parsed if the args are literals, and once per call otherwise
+	Fold bool
+	// Recover turns panics of the wrapped functions into bloblang
+	// errors naming the function and summarising its args
+	Recover bool
+	// Safe leaves out the functions classified as EffectsDangerous
+	Safe bool
+	// Config is the project config file, if one was given
+	Config *Config
 }
 
+const (
+	NonFiniteError  = "error"
+	NonFiniteNull   = "null"
+	NonFiniteString = "string"
+
+	BigUintNumber = "number"
+	BigUintString = "string"
+
+	IterPairsArray  = "array"
+	IterPairsObject = "object"
+
+	DefaultMaxElements = 10000
+	DefaultChanWait    = time.Second
+
+	EnumResultsName   = "name"
+	EnumResultsNumber = "number"
+
+	// ResultsArray returns results as an array in declaration order,
+	// ResultsNamed as an object keyed by their declared names, with
+	// r0, r1, ... for unnamed ones, and ResultsIndexed as an object
+	// keyed r0, r1, ... regardless of names
+	ResultsArray   = "array"
+	ResultsNamed   = "named"
+	ResultsIndexed = "indexed"
+
+	// NamingLower lower cases function names, LevenshteinDistance
+	// becomes levenshteindistance, NamingSnake snake cases them,
+	// levenshtein_distance, and NamingModule also puts the module in
+	// front, edlib_levenshtein_distance
+	NamingLower  = "lower"
+	NamingSnake  = "snake"
+	NamingModule = "module"
+
+	// BuiltinClashSkip leaves functions named like a built-in out,
+	// BuiltinClashPrefix puts the module in front of their name and
+	// BuiltinClashAlias registers them under the alias set in the
+	// project config, leaving out those without one
+	BuiltinClashSkip   = "skip"
+	BuiltinClashPrefix = "prefix"
+	BuiltinClashAlias  = "alias"
+
+	// EffectsPure functions only compute their results,
+	// EffectsUnknown ones call what can't be followed, such as
+	// methods, so they may not be pure, EffectsImpure ones read the
+	// state of the machine, such as the environment, files or the
+	// clock, and EffectsDangerous ones change it, remove files, reach
+	// the network or end the process
+	EffectsPure      = "pure"
+	EffectsUnknown   = "unknown"
+	EffectsImpure    = "impure"
+	EffectsDangerous = "dangerous"
+)
+
 type Arg struct {
 	Name string
 	Type string
+	// Param is the bloblang name of the param, set when the project
+	// config renames it
+	Param string
+	// Default is the Go literal of the value an omitted param takes,
+	// set from the project config
+	Default string
 }
 
 type Constant struct {
 	Name  string
+	Type  string
 	Value string
 }
 
+// Skip records a function left out of the generated code
+type Skip struct {
+	Function string
+	Reason   string
+}
+
 type Function struct {
 	Name        string
 	Description string
-	Args        []Arg
-	Return      []Arg
+	// Signature is the declaration of the function as go doc shows it
+	Signature string
+	Args      []Arg
+	Return    []Arg
+	// Dst is the buffer arg the plugin allocates itself, DstSize
+	// the expression giving its length
+	Dst     string
+	DstSize string
+	// Out is the arg the function writes into, returned by the
+	// plugin in place of the function's results
+	Out string
+	// Examples are added to the plugin spec for documentation
+	Examples []Example
+	// BloblangName is the name the function is registered under when
+	// it had to be renamed
+	BloblangName string
+	// Effects is the side-effect class of the function, one of
+	// EffectsPure, EffectsUnknown, EffectsImpure or EffectsDangerous
+	Effects string
 }
@@ -18,9 +18,11 @@ var native = []string{
 	"error",
 }
 
-// Check to see if function accepts and returns
-// only primitive types
-func checkValidFunction(f *Function) bool {
+var integers = []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64"}
+
+// Check to see if function accepts only primitive
+// types or types with a registered adapter
+func (mod *Module) checkValidFunction(f *Function) bool {
 	if f == nil {
 		return false
 	}
@@ -36,7 +38,27 @@ func checkValidFunction(f *Function) bool {
 			}
 		}*/
 
-	for _, a := range f.Args {
+	for i, a := range f.Args {
+		// an error can't be built from a mapping, so os.IsExist and
+		// the like are left out
+		if a.Type == "error" {
+			return false
+		}
+		if _, ok := pointerElem(a.Type); ok {
+			continue
+		}
+		if slices.Contains(passthrough, a.Type) {
+			continue
+		}
+		if i == len(f.Args)-1 && isVariadic(*f) {
+			continue
+		}
+		if a := mod.adapterFor(a.Type); a != nil && a.ToGo != "" {
+			continue
+		}
+		if mod.enumFor(a.Type) != nil {
+			continue
+		}
 		if !slices.Contains(native, a.Type) {
 			return false
 		}
@@ -44,6 +66,16 @@ func checkValidFunction(f *Function) bool {
 	return true
 }
 
+// pointerElem returns the element type of a pointer to a native
+// scalar such as *int or *string
+func pointerElem(typeStr string) (string, bool) {
+	elem, ok := strings.CutPrefix(typeStr, "*")
+	if !ok || strings.HasPrefix(elem, "[]") || elem == "error" {
+		return "", false
+	}
+	return elem, slices.Contains(native, elem)
+}
+
 func checkIfDownloaded(packageName string) bool {
 	gopath := os.Getenv("GOPATH")
 
@@ -82,7 +114,12 @@ func parseReturn(arg string) (*Arg, error) {
 	arg = strings.TrimSpace(arg)
 
 	if strings.Contains(arg, " ") {
-		parts := strings.Split(arg, " ")
+		parts := splitOutside(arg, ' ')
+
+		// channel types contain a space of their own
+		if i := slices.IndexFunc(parts, isChanKeyword); i >= 0 {
+			parts = append(parts[:i], strings.Join(parts[i:], " "))
+		}
 
 		switch len(parts) {
 		case 1:
@@ -183,22 +220,16 @@ func parseFunctionArguments(args string) ([]Arg, error) {
 		argObjectList = append(argObjectList, *argObj)
 	}
 
-	// check to see if all arg typs have been resolved
-	resolve := make([]int, 0)
+	// args declared together, as in (a, b int), take the type
+	// of the next arg that has one
 	resolveToType := ""
-	for i, argObject := range argObjectList {
-		if argObject.Type == "" {
-			resolve = append(resolve, i)
+	for i := len(argObjectList) - 1; i >= 0; i-- {
+		if argObjectList[i].Type == "" {
+			argObjectList[i].Type = resolveToType
 			continue
 		}
 
-		if resolveToType == "" {
-			resolveToType = argObject.Type
-		}
-	}
-
-	for i := range resolve {
-		argObjectList[i].Type = resolveToType
+		resolveToType = argObjectList[i].Type
 	}
 
 	return argObjectList, nil
@@ -218,7 +249,7 @@ func parseReturnArguments(args string) ([]Arg, error) {
 
 	if strings.Contains(args, ",") {
 		// multiple argument case
-		arguments := strings.Split(args, ", ")
+		arguments := splitOutside(args, ',')
 
 		// iterate through arguments and add each to the arg list
 		for _, arg := range arguments {
@@ -265,6 +296,36 @@ func parseReturnArguments(args string) ([]Arg, error) {
 	return argObjectList, nil
 }
 
+// splitOutside splits s on the occurrences of sep that are not
+// inside type params, such as the comma and the space in
+// iter.Seq2[int, string]
+func splitOutside(s string, sep rune) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func isChanKeyword(s string) bool {
+	return s == "chan" || s == "<-chan" || s == "chan<-"
+}
+
 func parseFunction(def string) (*Function, error) {
 	var (
 		err        error
@@ -314,14 +375,30 @@ func parseFunction(def string) (*Function, error) {
 }
 
 func toBenthosType(typeStr string) string {
+	if _, ok := pointerElem(typeStr); ok {
+		// optional params are taken raw so that null can map to nil
+		return "Any"
+	}
+
 	switch typeStr {
+	case "any", "interface{}":
+		return "Any"
 	case "float", "float32", "float64":
 		return "Float64"
-	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+	case "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32":
 		return "Int64"
+	case "byte", "rune":
+		// a single character or its code point
+		return "Any"
+	case "uint", "uint64":
+		// values above math.MaxInt64 don't survive GetInt64, so
+		// take the raw value and range check it ourselves
+		return "Any"
 	case "string":
 		return "String"
-	case "[]byte", "[]string":
+	case "bool":
+		return "Bool"
+	case "[]byte", "[]string", "[]bool", "[]rune":
 		return "Any"
 	case "[]int", "[]int8", "[]int16", "[]int32", "[]int64", "[]uint", "[]uint8", "[]uint16", "[]uint32", "[]uint64":
 		return "Any"
@@ -332,11 +409,13 @@ func toBenthosType(typeStr string) string {
 	}
 }
 
-func getFileName(name string) string {
-	if strings.Contains(name, "/") {
-		tmp := strings.Split(name, "/")
-		name = tmp[len(tmp)-1]
-	}
+// helpersFileName is the file holding the registry shared by
+// every module generated into the same directory
+const helpersFileName = "mod2blob.go"
 
-	return strings.ReplaceAll(name, "-", "_") + ".go"
-}
+// runtimeImport is the package holding the conversions the generated
+// code calls, imported as mod2blob
+const runtimeImport = "github.com/nibbleshift/mod2blob/runtime"
+
+// runtimeCall matches a call into the runtime package
+var runtimeCall = regexp.MustCompile(`\bmod2blob\.[A-Z]`)
@@ -2,16 +2,36 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/nibbleshift/argenv"
 	"github.com/nibbleshift/mod2blob/internal/module"
 )
 
 type Config struct {
-	Module    string `default:"" description:"Name of a go module such as 'math' or 'strings'"`
-	Prefix    string `default:"" description:"Prefix to use for function names. Format: [a-Z0-9]"`
-	Debug     bool   `default:"false" description:"Enable debug logging"`
-	OutputDir string `default:"." description:"Directory to write generated code to"`
+	Module           string `default:"" description:"Name of a go module such as 'math' or 'strings'"`
+	Prefix           string `default:"" description:"Prefix to use for function names. Format: [a-Z0-9]"`
+	Debug            bool   `default:"false" description:"Enable debug logging"`
+	OutputDir        string `default:"." description:"Directory to write generated code to"`
+	NonFinite        string `default:"error" description:"How NaN/Inf float results are returned: error, null or string"`
+	BigUint          string `default:"number" description:"How uint64 results above MaxInt64 are returned: number or string"`
+	MaxElements      int    `default:"10000" description:"Maximum number of elements collected from iterator and channel results"`
+	ChanWait         string `default:"1s" description:"Maximum time channel results are received from before what arrived is returned, as a channel may never be closed"`
+	IterPairs        string `default:"array" description:"How iter.Seq2 results are returned: array of [k, v] pairs or object"`
+	EnumResults      string `default:"name" description:"How enum results are returned: name of their constant or number"`
+	Results          string `default:"named" description:"How multiple results are returned: array, named (object of declared names) or indexed (object keyed r0, r1, ...)"`
+	Naming           string `default:"lower" description:"How function names become bloblang names: lower (levenshteindistance), snake (levenshtein_distance) or module (edlib_levenshtein_distance)"`
+	Builtins         string `default:"" description:"Path to the output of 'benthos list --format json bloblang-functions' to check names against instead of the built-in catalogue"`
+	BuiltinClash     string `default:"skip" description:"What happens to functions named like a bloblang built-in: skip, prefix (with the module) or alias (from the config)"`
+	Init             bool   `default:"true" description:"Also register the functions with the global bloblang environment from an init func"`
+	MaxStringBytes   int    `default:"0" description:"Maximum bytes of a string or byte array arg of a wrapped call, 0 for no limit"`
+	MaxArrayElements int    `default:"0" description:"Maximum elements of an array or object arg of a wrapped call, nested ones included, 0 for no limit"`
+	Timeout          string `default:"" description:"Maximum time a wrapped call may run, such as 500ms, empty for no limit"`
+	Memoize          int    `default:"0" description:"Cache the results of pure functions in an LRU cache of this many entries per function, 0 to disable"`
+	Fold             bool   `default:"false" description:"Call functions known to be pure once when the mapping is parsed if their args are literals, instead of once per message"`
+	Recover          bool   `default:"false" description:"Turn panics of wrapped functions into bloblang errors instead of crashing the process"`
+	Safe             bool   `default:"false" description:"Leave out dangerous functions, those that remove files, reach the network, change the environment or end the process"`
+	Config           string `default:"" description:"Path to a project config file with type adapters and per-function settings"`
 }
 
 func main() {
@@ -19,7 +39,71 @@ func main() {
 	config := &Config{}
 	argenv.Init(config)
 
-	pkg, err := module.LoadModule(config.Module, config.Prefix)
+	var (
+		err           error
+		projectConfig *module.Config
+	)
+
+	if config.Config != "" {
+		projectConfig, err = module.LoadConfig(config.Config)
+		if err != nil {
+			log.Println("Config: " + err.Error())
+			return
+		}
+	}
+
+	var builtins []string
+
+	if config.Builtins != "" {
+		builtins, err = module.LoadBuiltins(config.Builtins)
+		if err != nil {
+			log.Println("Builtins: " + err.Error())
+			return
+		}
+	}
+
+	var timeout time.Duration
+
+	if config.Timeout != "" {
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil {
+			log.Println("Timeout: " + err.Error())
+			return
+		}
+	}
+
+	var chanWait time.Duration
+
+	if config.ChanWait != "" {
+		chanWait, err = time.ParseDuration(config.ChanWait)
+		if err != nil {
+			log.Println("ChanWait: " + err.Error())
+			return
+		}
+	}
+
+	pkg, err := module.LoadModule(config.Module, module.Options{
+		Prefix:           config.Prefix,
+		NonFinite:        config.NonFinite,
+		BigUint:          config.BigUint,
+		MaxElements:      config.MaxElements,
+		ChanWait:         chanWait,
+		IterPairs:        config.IterPairs,
+		EnumResults:      config.EnumResults,
+		Results:          config.Results,
+		Naming:           config.Naming,
+		Builtins:         builtins,
+		BuiltinClash:     config.BuiltinClash,
+		Init:             config.Init,
+		MaxStringBytes:   config.MaxStringBytes,
+		MaxArrayElements: config.MaxArrayElements,
+		Timeout:          timeout,
+		Memoize:          config.Memoize,
+		Fold:             config.Fold,
+		Recover:          config.Recover,
+		Safe:             config.Safe,
+		Config:           projectConfig,
+	})
 	if err != nil {
 		log.Println(err)
 		return
@@ -30,4 +114,6 @@ func main() {
 		log.Println("Generate: " + err.Error())
 		return
 	}
+
+	log.Println(pkg.SkipReport())
 }
@@ -0,0 +1,192 @@
+package runtime
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseIP and FormatIP exchange net.IP as its string form.
+func ParseIP(v string) (net.IP, error) {
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", v)
+	}
+	return ip, nil
+}
+
+func FormatIP(v net.IP) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// ParseAddr and FormatAddr exchange netip.Addr as its string form.
+func ParseAddr(v string) (netip.Addr, error) {
+	return netip.ParseAddr(v)
+}
+
+func FormatAddr(v netip.Addr) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// ParsePrefix and FormatPrefix exchange netip.Prefix in CIDR notation.
+func ParsePrefix(v string) (netip.Prefix, error) {
+	return netip.ParsePrefix(v)
+}
+
+func FormatPrefix(v netip.Prefix) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// ParseURL and FormatURL exchange *url.URL as its string form.
+func ParseURL(v string) (*url.URL, error) {
+	return url.Parse(v)
+}
+
+func FormatURL(v *url.URL) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// bigString accepts big numbers as decimal strings, which is
+// the only lossless form, as well as plain bloblang numbers.
+func bigString(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	case int64, int, uint64:
+		return fmt.Sprint(t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("expected number or decimal string, got %T", v)
+}
+
+// ParseBigInt and FormatBigInt exchange *big.Int as a decimal string, accepting numbers too.
+func ParseBigInt(v any) (*big.Int, error) {
+	s, err := bigString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+func FormatBigInt(v *big.Int) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// ParseBigFloat and FormatBigFloat exchange *big.Float as a decimal string, accepting numbers too.
+func ParseBigFloat(v any) (*big.Float, error) {
+	s, err := bigString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := new(big.Float).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return f, nil
+}
+
+func FormatBigFloat(v *big.Float) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.Text('g', -1), nil
+}
+
+// ParseBigRat and FormatBigRat exchange *big.Rat as a fraction such as 1/3, accepting numbers too.
+func ParseBigRat(v any) (*big.Rat, error) {
+	s, err := bigString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid rational %q", s)
+	}
+	return r, nil
+}
+
+func FormatBigRat(v *big.Rat) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.RatString(), nil
+}
+
+// ParseUUID accepts the canonical 8-4-4-4-12 form as well as
+// 32 bare hex digits.
+func ParseUUID(v string) ([16]byte, error) {
+	var id [16]byte
+
+	s := strings.ReplaceAll(v, "-", "")
+	if len(s) != 32 {
+		return id, fmt.Errorf("invalid UUID %q", v)
+	}
+
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, fmt.Errorf("invalid UUID %q: %w", v, err)
+	}
+	return id, nil
+}
+
+// FormatUUID renders v in the canonical 8-4-4-4-12 form.
+func FormatUUID(v [16]byte) (any, error) {
+	s := hex.EncodeToString(v[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+// ParseRegexp and FormatRegexp exchange *regexp.Regexp as its pattern.
+func ParseRegexp(v string) (*regexp.Regexp, error) {
+	return regexp.Compile(v)
+}
+
+func FormatRegexp(v *regexp.Regexp) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// ParseLocation and FormatLocation exchange *time.Location as its IANA name.
+func ParseLocation(v string) (*time.Location, error) {
+	return time.LoadLocation(v)
+}
+
+func FormatLocation(v *time.Location) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAdapters(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  any
+		format func(any) (any, error)
+		want   any
+	}{
+		{name: "IP", input: "::ffff:10.0.0.1", want: "10.0.0.1", format: func(v any) (any, error) {
+			ip, err := ParseIP(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatIP(ip)
+		}},
+		{name: "Addr", input: "2001:db8::1", want: "2001:db8::1", format: func(v any) (any, error) {
+			a, err := ParseAddr(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatAddr(a)
+		}},
+		{name: "Prefix", input: "10.0.0.0/8", want: "10.0.0.0/8", format: func(v any) (any, error) {
+			p, err := ParsePrefix(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatPrefix(p)
+		}},
+		{name: "URL", input: "https://example.com/a?b=c", want: "https://example.com/a?b=c", format: func(v any) (any, error) {
+			u, err := ParseURL(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatURL(u)
+		}},
+		{name: "BigInt", input: "123456789012345678901234567890", want: "123456789012345678901234567890", format: func(v any) (any, error) {
+			n, err := ParseBigInt(v)
+			if err != nil {
+				return nil, err
+			}
+			return FormatBigInt(n)
+		}},
+		{name: "BigInt number", input: int64(42), want: "42", format: func(v any) (any, error) {
+			n, err := ParseBigInt(v)
+			if err != nil {
+				return nil, err
+			}
+			return FormatBigInt(n)
+		}},
+		{name: "BigFloat", input: 1.5, want: "1.5", format: func(v any) (any, error) {
+			f, err := ParseBigFloat(v)
+			if err != nil {
+				return nil, err
+			}
+			return FormatBigFloat(f)
+		}},
+		{name: "BigRat", input: "2/6", want: "1/3", format: func(v any) (any, error) {
+			r, err := ParseBigRat(v)
+			if err != nil {
+				return nil, err
+			}
+			return FormatBigRat(r)
+		}},
+		{name: "UUID", input: "6BA7B8109DAD11D180B400C04FD430C8", want: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", format: func(v any) (any, error) {
+			id, err := ParseUUID(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatUUID(id)
+		}},
+		{name: "Regexp", input: "^a+$", want: "^a+$", format: func(v any) (any, error) {
+			re, err := ParseRegexp(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatRegexp(re)
+		}},
+		{name: "Location", input: "UTC", want: "UTC", format: func(v any) (any, error) {
+			loc, err := ParseLocation(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			return FormatLocation(loc)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.format(tt.input)
+			if err != nil || got != tt.want {
+				t.Errorf("got %#v, %v, want %#v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdapterErrors(t *testing.T) {
+	if _, err := ParseIP("10.0.0"); err == nil {
+		t.Error("IP: expected an error")
+	}
+	if _, err := ParseUUID("6ba7b810"); err == nil {
+		t.Error("UUID: expected an error")
+	}
+	if _, err := ParseBigInt("1.5"); err == nil {
+		t.Error("BigInt: expected an error")
+	}
+	if _, err := ParseBigRat(true); err == nil {
+		t.Error("BigRat: expected an error")
+	}
+}
+
+func TestFormatNil(t *testing.T) {
+	for name, format := range map[string]func() (any, error){
+		"IP":     func() (any, error) { return FormatIP(nil) },
+		"URL":    func() (any, error) { return FormatURL(nil) },
+		"BigInt": func() (any, error) { return FormatBigInt((*big.Int)(nil)) },
+		"Regexp": func() (any, error) { return FormatRegexp(nil) },
+	} {
+		if v, err := format(); v != nil || err != nil {
+			t.Errorf("%s: got %v, %v, want null", name, v, err)
+		}
+	}
+}
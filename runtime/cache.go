@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CacheStats are the counters of the memoisation cache of a plugin.
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Size     int
+	Capacity int
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = map[string][]*Cache{}
+)
+
+// Caches returns the counters of every memoised plugin generated into
+// this package, keyed by its bloblang name. A plugin registered into
+// several environments has a cache in each, whose counters are
+// summed.
+func Caches() map[string]CacheStats {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	stats := make(map[string]CacheStats, len(caches))
+	for name, cs := range caches {
+		var sum CacheStats
+		for _, c := range cs {
+			s := c.stats()
+			sum.Hits += s.Hits
+			sum.Misses += s.Misses
+			sum.Size += s.Size
+			sum.Capacity += s.Capacity
+		}
+		stats[name] = sum
+	}
+	return stats
+}
+
+// Cache is a bounded LRU cache of the results of a plugin,
+// keyed by the JSON encoding of its args and their types.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	hits     uint64
+	misses   uint64
+}
+
+type cacheEntry struct {
+	key string
+	v   any
+}
+
+// NewCache returns a cache of the plugin name, holding at
+// most capacity results, and adds it to Caches.
+func NewCache(name string, capacity int) *Cache {
+	c := &Cache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+
+	cachesMu.Lock()
+	caches[name] = append(caches[name], c)
+	cachesMu.Unlock()
+
+	return c
+}
+
+func (c *Cache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).v, true
+}
+
+func (c *Cache) add(key string, v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, v: v})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *Cache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Size: c.order.Len(), Capacity: c.capacity}
+}
+
+// Memoize wraps fn so that its results are cached by args.
+// Errors are not cached, and cached arrays and objects are copied
+// before they are handed out, so that mappings can't change them.
+func Memoize(c *Cache, args []any, fn Function) Function {
+	return func() (any, error) {
+		raw, err := json.Marshal(typed(args))
+		if err != nil {
+			return fn()
+		}
+		key := string(raw)
+
+		if v, ok := c.get(key); ok {
+			return Copy(v), nil
+		}
+
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		c.add(key, v)
+		return Copy(v), nil
+	}
+}
+
+// typed pairs every value in v with its type, so that the
+// cache keys of a byte array and its base64 string, or of 1 and 1.0,
+// differ.
+func typed(v any) any {
+	switch t := v.(type) {
+	case []any:
+		c := make([]any, len(t))
+		for i, e := range t {
+			c[i] = typed(e)
+		}
+		return c
+	case map[string]any:
+		c := make(map[string]any, len(t))
+		for k, e := range t {
+			c[k] = typed(e)
+		}
+		return c
+	}
+	return [2]any{fmt.Sprintf("%T", v), v}
+}
+
+// Fold calls fn right away and returns a function yielding
+// its result, copied for every call.
+func Fold(fn Function) Function {
+	v, err := fn()
+	return func() (any, error) {
+		return Copy(v), err
+	}
+}
+
+// Copy returns a deep copy of the arrays, objects and byte
+// arrays in v.
+func Copy(v any) any {
+	switch t := v.(type) {
+	case []any:
+		c := make([]any, len(t))
+		for i, e := range t {
+			c[i] = Copy(e)
+		}
+		return c
+	case map[string]any:
+		c := make(map[string]any, len(t))
+		for k, e := range t {
+			c[k] = Copy(e)
+		}
+		return c
+	case []byte:
+		return append([]byte(nil), t...)
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package runtime
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoize(t *testing.T) {
+	cache := NewCache("test_memoize", 2)
+
+	calls := 0
+	call := func(arg any) (any, error) {
+		return Memoize(cache, []any{arg}, func() (any, error) {
+			calls++
+			return []any{arg}, nil
+		})()
+	}
+
+	for _, arg := range []string{"a", "b", "a"} {
+		if v, err := call(arg); err != nil || !reflect.DeepEqual(v, []any{arg}) {
+			t.Fatalf("%s: got %v, %v", arg, v, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("a, b, a: %d calls, want 2", calls)
+	}
+
+	// c evicts b, the least recently used
+	_, _ = call("c")
+	_, _ = call("b")
+	if calls != 4 {
+		t.Errorf("after c and b: %d calls, want 4", calls)
+	}
+
+	want := CacheStats{Hits: 1, Misses: 4, Size: 2, Capacity: 2}
+	if got := Caches()["test_memoize"]; got != want {
+		t.Errorf("stats: got %+v, want %+v", got, want)
+	}
+
+	// cached results are copies
+	v, _ := call("b")
+	v.([]any)[0] = "changed"
+	if v, _ := call("b"); !reflect.DeepEqual(v, []any{"b"}) {
+		t.Errorf("cached result changed to %v", v)
+	}
+}
+
+func TestMemoizeTypes(t *testing.T) {
+	cache := NewCache("test_memoize_types", 10)
+
+	calls := 0
+	call := func(arg any) {
+		_, _ = Memoize(cache, []any{arg}, func() (any, error) {
+			calls++
+			return arg, nil
+		})()
+	}
+
+	// the same JSON encoding, but different values
+	for _, arg := range []any{[]byte("a"), "YQ==", int64(1), float64(1), []any{int64(1)}, []any{float64(1)}} {
+		call(arg)
+	}
+	if calls != 6 {
+		t.Errorf("%d calls, want 6", calls)
+	}
+
+	// a second environment registering the same plugin
+	other := NewCache("test_memoize_types", 10)
+	_, _ = Memoize(other, []any{"x"}, func() (any, error) { return "x", nil })()
+
+	want := CacheStats{Misses: 7, Size: 7, Capacity: 20}
+	if got := Caches()["test_memoize_types"]; got != want {
+		t.Errorf("stats: got %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoizeErrors(t *testing.T) {
+	cache := NewCache("test_memoize_errors", 10)
+
+	calls := 0
+	fn := Memoize(cache, []any{"x"}, func() (any, error) {
+		calls++
+		return nil, errors.New("failed")
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := fn(); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("%d calls, want 2 as errors aren't cached", calls)
+	}
+}
+
+func TestFold(t *testing.T) {
+	calls := 0
+	fn := Fold(func() (any, error) {
+		calls++
+		return map[string]any{"a": []any{1}}, nil
+	})
+
+	v, _ := fn()
+	v.(map[string]any)["a"] = nil
+	if v, _ := fn(); !reflect.DeepEqual(v, map[string]any{"a": []any{1}}) {
+		t.Errorf("folded result changed to %v", v)
+	}
+	if calls != 1 {
+		t.Errorf("%d calls, want 1", calls)
+	}
+}
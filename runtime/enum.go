@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"fmt"
+)
+
+// EnumConst is a named constant of an enum type
+type EnumConst[T comparable] struct {
+	Name  string
+	Value T
+}
+
+// EnumInt accepts the name of one of consts or a number
+func EnumInt[T Integer](name string, v any, consts []EnumConst[T]) (T, error) {
+	if s, ok := v.(string); ok {
+		for _, c := range consts {
+			if c.Name == s {
+				return c.Value, nil
+			}
+		}
+		return 0, fmt.Errorf("%s: unknown %T %q", name, T(0), s)
+	}
+	return AnyInt[T](name, v)
+}
+
+// EnumString accepts the name of one of consts or any other
+// string, which is passed as it is
+func EnumString[T ~string](name string, v any, consts []EnumConst[T]) (T, error) {
+	s, err := AnyString(name, v)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range consts {
+		if c.Name == s {
+			return c.Value, nil
+		}
+	}
+	return T(s), nil
+}
+
+// EnumName returns the name of the first of consts with the
+// value v, or v itself under the NaN/Inf and big uint policies when
+// none has it
+func EnumName[T comparable](name string, v T, consts []EnumConst[T], nonFinite string, bigUint string) (any, error) {
+	for _, c := range consts {
+		if c.Value == v {
+			return c.Name, nil
+		}
+	}
+	return Normalise(name, v, nonFinite, bigUint)
+}
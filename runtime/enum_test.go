@@ -0,0 +1,25 @@
+package runtime
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnumName(t *testing.T) {
+	type Size uint64
+	consts := []EnumConst[Size]{{Name: "Small", Value: 1}, {Name: "Large", Value: 2}}
+
+	if v, err := EnumName("f", Size(2), consts, "error", "number"); v != "Large" || err != nil {
+		t.Errorf("a constant: got %v, %v", v, err)
+	}
+	if v, err := EnumName("f", Size(math.MaxUint64), consts, "error", "string"); v != "18446744073709551615" || err != nil {
+		t.Errorf("a big uint under the string policy: got %#v, %v", v, err)
+	}
+
+	if v, err := EnumInt("c", "Small", consts); v != 1 || err != nil {
+		t.Errorf("by name: got %v, %v", v, err)
+	}
+	if _, err := EnumInt("c", "Medium", consts); err == nil {
+		t.Error("an unknown name: expected an error")
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/nibbleshift/mod2blob/runtime
+
+go 1.22.2
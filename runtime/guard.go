@@ -0,0 +1,176 @@
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recover wraps fn so that a panic in the wrapped function
+// is returned as an error naming the plugin and summarising args,
+// instead of taking the process down.
+func Recover(name string, args []any, fn Function) Function {
+	return func() (res any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				res, err = nil, fmt.Errorf("%s(%s) panicked: %v", name, argSummary(args), r)
+			}
+		}()
+		return fn()
+	}
+}
+
+// argSummary renders args for an error message, cutting long
+// values short.
+func argSummary(args []any) string {
+	const max = 32
+
+	parts := make([]string, len(args))
+	for i, a := range args {
+		var s string
+		switch v := a.(type) {
+		case string:
+			if len(v) > max {
+				s = fmt.Sprintf("%q...", v[:max])
+			} else {
+				s = strconv.Quote(v)
+			}
+		case []byte:
+			s = fmt.Sprintf("<%d bytes>", len(v))
+		case []any:
+			s = fmt.Sprintf("<array of %d>", len(v))
+		case map[string]any:
+			s = fmt.Sprintf("<object of %d>", len(v))
+		default:
+			s = fmt.Sprintf("%v", v)
+			if len(s) > max {
+				s = s[:max] + "..."
+			}
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Limits are the resource guards of a plugin, zero meaning
+// unlimited.
+type Limits struct {
+	MaxStringBytes   int
+	MaxArrayElements int
+	Timeout          time.Duration
+	// Counts are the indexes of the integer args that size the
+	// result, such as the count of strings.Repeat. They may be no
+	// larger than the larger of the size limits.
+	Counts []int
+}
+
+// Guard wraps fn so that it fails without running when args
+// exceed the size limits, fails instead of returning a result that
+// exceeds them, and stops waiting for it once it runs past the
+// timeout. A call that times out can't be interrupted and keeps
+// running in the background until it returns.
+func Guard(name string, limits Limits, args []any, fn Function) Function {
+	return func() (any, error) {
+		for i, a := range args {
+			if err := limits.check(a); err != nil {
+				return nil, fmt.Errorf("%s: argument %d %w", name, i, err)
+			}
+		}
+		for _, i := range limits.Counts {
+			if err := limits.checkCount(args[i]); err != nil {
+				return nil, fmt.Errorf("%s: argument %d %w", name, i, err)
+			}
+		}
+
+		v, err := limits.run(name, fn)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.check(v); err != nil {
+			return nil, fmt.Errorf("%s: result %w", name, err)
+		}
+		return v, nil
+	}
+}
+
+// run calls fn, giving up on it once it runs past the timeout
+func (l Limits) run(name string, fn Function) (any, error) {
+	if l.Timeout <= 0 {
+		return fn()
+	}
+
+	type result struct {
+		v   any
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+
+	timer := time.NewTimer(l.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("%s: timed out after %v", name, l.Timeout)
+	}
+}
+
+// checkCount fails when the count v is larger than the larger of
+// the size limits. Values that aren't integers are left to the
+// conversion of the arg to report.
+func (l Limits) checkCount(v any) error {
+	limit := max(l.MaxStringBytes, l.MaxArrayElements)
+	if limit <= 0 {
+		return nil
+	}
+
+	n, err := Int64("", v)
+	if err != nil {
+		return nil
+	}
+	if n > int64(limit) {
+		return fmt.Errorf("asks for %d, the limit is %d", n, limit)
+	}
+	return nil
+}
+
+// check fails when v, or any value nested in it, exceeds the size
+// limits.
+func (l Limits) check(v any) error {
+	switch t := v.(type) {
+	case string:
+		if l.MaxStringBytes > 0 && len(t) > l.MaxStringBytes {
+			return fmt.Errorf("is %d bytes long, the limit is %d", len(t), l.MaxStringBytes)
+		}
+	case []byte:
+		if l.MaxStringBytes > 0 && len(t) > l.MaxStringBytes {
+			return fmt.Errorf("is %d bytes long, the limit is %d", len(t), l.MaxStringBytes)
+		}
+	case []any:
+		if l.MaxArrayElements > 0 && len(t) > l.MaxArrayElements {
+			return fmt.Errorf("has %d elements, the limit is %d", len(t), l.MaxArrayElements)
+		}
+		for _, e := range t {
+			if err := l.check(e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if l.MaxArrayElements > 0 && len(t) > l.MaxArrayElements {
+			return fmt.Errorf("has %d elements, the limit is %d", len(t), l.MaxArrayElements)
+		}
+		for _, e := range t {
+			if err := l.check(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	fn := Recover("repeat", []any{"abc", int64(-1)}, func() (any, error) {
+		panic("strings: negative Repeat count")
+	})
+
+	v, err := fn()
+	if v != nil || err == nil {
+		t.Fatalf("got %v, %v", v, err)
+	}
+	if want := `repeat("abc", -1) panicked: strings: negative Repeat count`; err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+
+	fn = Recover("abs", []any{-1.5}, func() (any, error) { return 1.5, nil })
+	if v, err := fn(); v != 1.5 || err != nil {
+		t.Errorf("without a panic: got %v, %v", v, err)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	calls := 0
+	ok := func() (any, error) {
+		calls++
+		return "ok", nil
+	}
+	limits := Limits{MaxStringBytes: 4, MaxArrayElements: 2}
+
+	tests := []struct {
+		args []any
+		err  string
+	}{
+		{args: []any{"abcd", []any{1, 2}}},
+		{args: []any{"abcde"}, err: "argument 0 is 5 bytes long, the limit is 4"},
+		{args: []any{[]byte("abcde")}, err: "argument 0 is 5 bytes long"},
+		{args: []any{"a", []any{1, 2, 3}}, err: "argument 1 has 3 elements, the limit is 2"},
+		{args: []any{map[string]any{"a": []any{"abcde"}}}, err: "argument 0 is 5 bytes long"},
+	}
+
+	for _, tt := range tests {
+		calls = 0
+		_, err := Guard("f", limits, tt.args, ok)()
+		if tt.err == "" {
+			if err != nil || calls != 1 {
+				t.Errorf("%v: got %v after %d calls", tt.args, err, calls)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) || calls != 0 {
+			t.Errorf("%v: got %v after %d calls, want %q", tt.args, err, calls, tt.err)
+		}
+	}
+}
+
+func TestGuardSizes(t *testing.T) {
+	limits := Limits{MaxStringBytes: 100, Counts: []int{1}}
+	repeat := func(s string, count int64) Function {
+		return Guard("repeat", limits, []any{s, count}, func() (any, error) {
+			return strings.Repeat(s, int(count)), nil
+		})
+	}
+
+	if _, err := repeat("x", 1000)(); err == nil || err.Error() != "repeat: argument 1 asks for 1000, the limit is 100" {
+		t.Errorf("count over the limit: got %v", err)
+	}
+	if _, err := repeat("abc", 50)(); err == nil || err.Error() != "repeat: result is 150 bytes long, the limit is 100" {
+		t.Errorf("result over the limit: got %v", err)
+	}
+	if v, err := repeat("x", 100)(); v != strings.Repeat("x", 100) || err != nil {
+		t.Errorf("within the limits: got %v, %v", v, err)
+	}
+}
+
+func TestGuardTimeout(t *testing.T) {
+	slow := func() (any, error) {
+		time.Sleep(time.Second)
+		return "late", nil
+	}
+
+	start := time.Now()
+	_, err := Guard("sleep", Limits{Timeout: 20 * time.Millisecond}, nil, slow)()
+	if err == nil || err.Error() != "sleep: timed out after 20ms" {
+		t.Fatalf("got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %s", elapsed)
+	}
+
+	fast := func() (any, error) { return "ok", nil }
+	if v, err := Guard("f", Limits{Timeout: time.Second}, nil, fast)(); v != "ok" || err != nil {
+		t.Errorf("within the timeout: got %v, %v", v, err)
+	}
+}
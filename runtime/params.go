@@ -0,0 +1,292 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Integer is the set of Go integer types params are narrowed to
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Int narrows v to T, failing instead of wrapping when v
+// does not fit.
+func Int[T Integer](name string, v int64) (T, error) {
+	r := T(v)
+	if int64(r) != v || (v < 0) != (r < 0) {
+		return 0, fmt.Errorf("%s: %d overflows %T", name, v, r)
+	}
+	return r, nil
+}
+
+// Uint accepts any non-negative integer, including values
+// above math.MaxInt64 given as uint64 or as a decimal string.
+func Uint[T ~uint | ~uint64](name string, v any) (T, error) {
+	var u uint64
+
+	switch t := v.(type) {
+	case uint64:
+		u = t
+	case int64:
+		if t < 0 {
+			return 0, fmt.Errorf("%s: %d overflows %T", name, t, T(0))
+		}
+		u = uint64(t)
+	case int:
+		if t < 0 {
+			return 0, fmt.Errorf("%s: %d overflows %T", name, t, T(0))
+		}
+		u = uint64(t)
+	case float64:
+		if t < 0 || t >= 1<<64 || t != math.Trunc(t) {
+			return 0, fmt.Errorf("%s: %v is not representable as %T", name, t, T(0))
+		}
+		u = uint64(t)
+	case json.Number:
+		return Uint[T](name, string(t))
+	case string:
+		var err error
+		u, err = strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+	default:
+		return 0, fmt.Errorf("%s: expected unsigned integer, got %T", name, v)
+	}
+
+	r := T(u)
+	if uint64(r) != u {
+		return 0, fmt.Errorf("%s: %d overflows %T", name, u, r)
+	}
+	return r, nil
+}
+
+// Int64 extracts an integer from a bloblang number, rejecting
+// floats with a fractional part.
+func Int64(name string, v any) (int64, error) {
+	switch t := v.(type) {
+	case int64:
+		return t, nil
+	case int:
+		return int64(t), nil
+	case uint64:
+		if t > math.MaxInt64 {
+			return 0, fmt.Errorf("%s: %d overflows int64", name, t)
+		}
+		return int64(t), nil
+	case float64:
+		if t < math.MinInt64 || t >= math.MaxInt64 || t != math.Trunc(t) {
+			return 0, fmt.Errorf("%s: %v is not representable as int64", name, t)
+		}
+		return int64(t), nil
+	case json.Number:
+		i, err := t.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("%s: expected integer, got %T", name, v)
+}
+
+// Float32 narrows v to float32, failing when a finite v
+// would become ±Inf.
+func Float32(name string, v float64) (float32, error) {
+	if !math.IsInf(v, 0) && !math.IsNaN(v) && math.Abs(v) > math.MaxFloat32 {
+		return 0, fmt.Errorf("%s: %v overflows float32", name, v)
+	}
+	return float32(v), nil
+}
+
+// Array accepts a bloblang array
+func Array(name string, v any) ([]any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected array, got %T", name, v)
+	}
+	return arr, nil
+}
+
+// AnyInt converts a number param taken as any to the integer type T
+func AnyInt[T Integer](name string, v any) (T, error) {
+	n, err := Int64(name, v)
+	if err != nil {
+		return 0, err
+	}
+	return Int[T](name, n)
+}
+
+// Char accepts a byte or rune param as a single character, so that
+// "e" can be passed for 'e', or as its code point
+func Char[T ~uint8 | ~int32](name string, v any) (T, error) {
+	s, ok := v.(string)
+	if !ok {
+		return AnyInt[T](name, v)
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == utf8.RuneError {
+		return 0, fmt.Errorf("%s: expected a single character, got %q", name, s)
+	}
+
+	// a byte is one byte of s, so é, which takes two, is refused
+	// rather than taken as its code point
+	if wide := 256; T(wide) == 0 && size != 1 {
+		return 0, fmt.Errorf("%s: expected a single byte, got %q", name, s)
+	}
+	return T(r), nil
+}
+
+// AnyFloat converts a number param taken as any to the float type T
+func AnyFloat[T ~float32 | ~float64](name string, v any) (T, error) {
+	var f float64
+
+	switch t := v.(type) {
+	case float64:
+		f = t
+	case int64:
+		f = float64(t)
+	case int:
+		f = float64(t)
+	case uint64:
+		f = float64(t)
+	case json.Number:
+		var err error
+		if f, err = t.Float64(); err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+	default:
+		return 0, fmt.Errorf("%s: expected number, got %T", name, v)
+	}
+
+	var r T
+	if _, isFloat32 := any(r).(float32); isFloat32 {
+		if _, err := Float32(name, f); err != nil {
+			return 0, err
+		}
+	}
+	return T(f), nil
+}
+
+// AnyString accepts a string param taken as any
+func AnyString(name string, v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case []byte:
+		return string(t), nil
+	}
+	return "", fmt.Errorf("%s: expected string, got %T", name, v)
+}
+
+// AnyBool accepts a bool param taken as any
+func AnyBool(name string, v any) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: expected bool, got %T", name, v)
+	}
+	return b, nil
+}
+
+// Optional converts an optional param, leaving it nil when it
+// was omitted or given as null.
+func Optional[T any](name string, v any, conv func(string, any) (T, error)) (*T, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	r, err := conv(name, v)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Ints converts an array of numbers to a slice of the integer type T
+func Ints[T Integer](name string, v any) ([]T, error) {
+	arr, err := Array(name, v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, len(arr))
+	for i, e := range arr {
+		if out[i], err = AnyInt[T](fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Uints converts an array of non-negative numbers to a slice of T
+func Uints[T ~uint | ~uint64](name string, v any) ([]T, error) {
+	arr, err := Array(name, v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, len(arr))
+	for i, e := range arr {
+		if out[i], err = Uint[T](fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Floats converts an array of numbers to a slice of the float type T
+func Floats[T ~float32 | ~float64](name string, v any) ([]T, error) {
+	arr, err := Array(name, v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, len(arr))
+	for i, e := range arr {
+		if out[i], err = AnyFloat[T](fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Bools converts an array of bools to a slice
+func Bools(name string, v any) ([]bool, error) {
+	arr, err := Array(name, v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]bool, len(arr))
+	for i, e := range arr {
+		if out[i], err = AnyBool(fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Bytes accepts a string or a byte array
+func Bytes(name string, v any) ([]byte, error) {
+	switch t := v.(type) {
+	case []byte:
+		// copied, as functions may write into it
+		return append([]byte{}, t...), nil
+	case string:
+		return []byte(t), nil
+	}
+	return nil, fmt.Errorf("%s: expected string or bytes, got %T", name, v)
+}
+
+// RawArgs checks that a variadic call was given at least the
+// fixed params that come before the variadic one.
+func RawArgs(raw []any, fixed int) ([]any, error) {
+	if len(raw) < fixed {
+		return nil, fmt.Errorf("expected at least %d arguments, got %d", fixed, len(raw))
+	}
+	return raw, nil
+}
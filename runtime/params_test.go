@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestInt(t *testing.T) {
+	if v, err := Int[int8]("x", 127); err != nil || v != 127 {
+		t.Errorf("127 as int8: got %v, %v", v, err)
+	}
+	if v, err := Int[int8]("x", -128); err != nil || v != -128 {
+		t.Errorf("-128 as int8: got %v, %v", v, err)
+	}
+
+	for _, v := range []int64{128, -129} {
+		if _, err := Int[int8]("x", v); err == nil {
+			t.Errorf("%d as int8: expected an error", v)
+		}
+	}
+
+	if _, err := Int[uint]("x", -1); err == nil {
+		t.Error("-1 as uint: expected an error")
+	}
+	if _, err := Int[uint32]("x", math.MaxUint32+1); err == nil {
+		t.Error("MaxUint32+1 as uint32: expected an error")
+	}
+}
+
+func TestChar(t *testing.T) {
+	if v, err := Char[byte]("fmt", "e"); err != nil || v != 'e' {
+		t.Errorf(`"e" as byte: got %v, %v`, v, err)
+	}
+	if v, err := Char[byte]("fmt", int64(102)); err != nil || v != 'f' {
+		t.Errorf("102 as byte: got %v, %v", v, err)
+	}
+	if v, err := Char[rune]("r", "é"); err != nil || v != 'é' {
+		t.Errorf(`"é" as rune: got %v, %v`, v, err)
+	}
+
+	for _, v := range []any{"", "ef", "é", int64(256)} {
+		if _, err := Char[byte]("fmt", v); err == nil {
+			t.Errorf("%#v as byte: expected an error", v)
+		}
+	}
+}
+
+func TestUint(t *testing.T) {
+	tests := []struct {
+		input any
+		want  uint64
+		err   bool
+	}{
+		{input: int64(42), want: 42},
+		{input: uint64(math.MaxUint64), want: math.MaxUint64},
+		{input: "18446744073709551615", want: math.MaxUint64},
+		{input: json.Number("7"), want: 7},
+		{input: float64(3), want: 3},
+		{input: int64(-1), err: true},
+		{input: float64(1.5), err: true},
+		{input: "18446744073709551616", err: true},
+		{input: true, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Uint[uint64]("x", tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%#v: expected an error, got %d", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%#v: got %d, %v, want %d", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestInt64(t *testing.T) {
+	if v, err := Int64("x", float64(-3)); err != nil || v != -3 {
+		t.Errorf("-3.0: got %v, %v", v, err)
+	}
+
+	for _, v := range []any{2.5, math.Inf(1), uint64(math.MaxUint64), "1"} {
+		if _, err := Int64("x", v); err == nil {
+			t.Errorf("%#v: expected an error", v)
+		}
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	if _, err := Float32("x", math.MaxFloat64); err == nil {
+		t.Error("MaxFloat64: expected an error")
+	}
+	if v, err := Float32("x", math.Inf(-1)); err != nil || !math.IsInf(float64(v), -1) {
+		t.Errorf("-Inf: got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,319 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NonFinite renders NaN and ±Inf according to policy,
+// which is one of "error", "null" or "string".
+func NonFinite(name string, v float64, policy string) (any, error) {
+	switch policy {
+	case "null":
+		return nil, nil
+	case "string":
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	return nil, fmt.Errorf("%s: result is %v", name, v)
+}
+
+// Float returns v, or what NonFinite renders it as when it is NaN or ±Inf
+func Float(name string, v float64, policy string) (any, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return NonFinite(name, v, policy)
+	}
+	return v, nil
+}
+
+// BigUint returns values that don't fit in an int64 as
+// decimal strings so they survive JSON consumers intact.
+func BigUint[T ~uint | ~uint64](v T) (any, error) {
+	if uint64(v) > math.MaxInt64 {
+		return strconv.FormatUint(uint64(v), 10), nil
+	}
+	return v, nil
+}
+
+// Deref returns what v points at, or null for a nil pointer.
+func Deref[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return *v, nil
+}
+
+// DerefFunc is Deref for results that still need
+// converting once dereferenced.
+func DerefFunc[T any](v *T, conv func(T) (any, error)) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return conv(*v)
+}
+
+// Seq collects an iter.Seq into an array of at most max
+// elements, failing rather than truncating a longer sequence. The
+// iter package isn't imported so that modules built before Go 1.23
+// still compile.
+func Seq[T any](name string, seq func(yield func(T) bool), max int, nonFinite string, bigUint string) (any, error) {
+	if seq == nil {
+		return nil, nil
+	}
+
+	out := []T{}
+	seq(func(v T) bool {
+		out = append(out, v)
+		return len(out) <= max
+	})
+
+	if len(out) > max {
+		return nil, fmt.Errorf("%s: result has more than %d elements", name, max)
+	}
+	return Normalise(name, out, nonFinite, bigUint)
+}
+
+// Seq2 collects an iter.Seq2 of at most max pairs into an
+// array of [k, v] arrays, or with pairs set to "object" into an
+// object keyed by k
+func Seq2[K, V any](name string, seq func(yield func(K, V) bool), max int, pairs string, nonFinite string, bigUint string) (any, error) {
+	if seq == nil {
+		return nil, nil
+	}
+
+	out := [][2]any{}
+	seq(func(k K, v V) bool {
+		out = append(out, [2]any{k, v})
+		return len(out) <= max
+	})
+
+	if len(out) > max {
+		return nil, fmt.Errorf("%s: result has more than %d elements", name, max)
+	}
+
+	if pairs == "object" {
+		obj := make(map[string]any, len(out))
+		for _, kv := range out {
+			obj[fmt.Sprint(kv[0])] = kv[1]
+		}
+		return Normalise(name, obj, nonFinite, bigUint)
+	}
+	return Normalise(name, out, nonFinite, bigUint)
+}
+
+// Chan receives from ch until it is closed or wait has passed,
+// failing once more than max elements arrive. A channel that is never
+// closed, such as the one of time.After, returns what arrived within
+// wait. The sender is left blocked when the call fails or wait
+// passes, as there is no way to stop it.
+func Chan[T any](name string, ch <-chan T, max int, wait time.Duration, nonFinite string, bigUint string) (any, error) {
+	if ch == nil {
+		return nil, nil
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	out := []T{}
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return Normalise(name, out, nonFinite, bigUint)
+			}
+			if len(out) == max {
+				return nil, fmt.Errorf("%s: result has more than %d elements", name, max)
+			}
+			out = append(out, v)
+		case <-deadline.C:
+			return Normalise(name, out, nonFinite, bigUint)
+		}
+	}
+}
+
+// Normalise turns an arbitrary Go value into one bloblang
+// understands: structs and maps become objects, slices and arrays
+// become arrays and pointers are followed. Floats and uint64s are
+// subject to the same policies as scalar results.
+func Normalise(name string, v any, nonFinite string, bigUint string) (any, error) {
+	return normaliseValue(name, reflect.ValueOf(v), nonFinite, bigUint, 0)
+}
+
+func normaliseValue(name string, v reflect.Value, nonFinite string, bigUint string, depth int) (any, error) {
+	if depth > 64 {
+		return nil, errors.New(name + ": result nested too deeply")
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.CanInterface() {
+		switch t := v.Interface().(type) {
+		case []byte, time.Time, json.Number:
+			return t, nil
+		case error:
+			if v.Kind() == reflect.Pointer && v.IsNil() {
+				return nil, nil
+			}
+			return t.Error(), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return normaliseValue(name, v.Elem(), nonFinite, bigUint, depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if bigUint == "string" {
+			return BigUint(v.Uint())
+		}
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(name, v.Float(), nonFinite)
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			var err error
+			if out[i], err = normaliseValue(name, v.Index(i), nonFinite, bigUint, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			keyStr := fmt.Sprint(key.Interface())
+			if key.Kind() == reflect.String {
+				keyStr = key.String()
+			}
+
+			var err error
+			if out[keyStr], err = normaliseValue(name, iter.Value(), nonFinite, bigUint, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case reflect.Struct:
+		out := map[string]any{}
+		if err := normaliseStruct(name, v, out, nonFinite, bigUint, depth); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s: cannot represent %s in bloblang", name, v.Type())
+}
+
+// normaliseStruct adds the exported fields of v to out, named,
+// flattened and left out the way encoding/json would, honouring the
+// omitempty, omitzero and string options.
+func normaliseStruct(name string, v reflect.Value, out map[string]any, nonFinite string, bigUint string, depth int) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldName := field.Name
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		tag, opts, _ := strings.Cut(jsonTag, ",")
+		if tag != "" {
+			fieldName = tag
+		} else if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := normaliseStruct(name, v.Field(i), out, nonFinite, bigUint, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fv := v.Field(i)
+		options := strings.Split(opts, ",")
+		if slices.Contains(options, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		if slices.Contains(options, "omitzero") && fv.IsZero() {
+			continue
+		}
+
+		r, err := normaliseValue(name, fv, nonFinite, bigUint, depth+1)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(options, "string") {
+			r = quoteField(fv, r)
+		}
+		out[fieldName] = r
+	}
+	return nil
+}
+
+// isEmptyValue reports whether encoding/json leaves v out of a
+// field tagged omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// quoteField renders the normalised value r of a field tagged with
+// the string option as encoding/json does: bools and numbers as
+// strings, and strings as their JSON encoding. Other kinds, and
+// values the policies turned into something else, are left as they
+// are.
+func quoteField(v reflect.Value, r any) any {
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+	default:
+		return r
+	}
+
+	if _, ok := r.(string); ok && v.Kind() != reflect.String {
+		// a big uint or non-finite float already rendered as a string
+		return r
+	}
+
+	raw, err := json.Marshal(v.Interface())
+	if err != nil {
+		return r
+	}
+	return string(raw)
+}
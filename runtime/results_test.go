@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChan(t *testing.T) {
+	closed := make(chan int, 3)
+	closed <- 1
+	closed <- 2
+	close(closed)
+
+	got, err := Chan("f", closed, 10, time.Second, "error", "number")
+	if err != nil || !reflect.DeepEqual(got, []any{int64(1), int64(2)}) {
+		t.Fatalf("closed channel: got %v, %v", got, err)
+	}
+
+	// a channel that is never closed returns what arrived within wait
+	open := make(chan int, 1)
+	open <- 1
+
+	start := time.Now()
+	got, err = Chan("f", open, 10, 50*time.Millisecond, "error", "number")
+	if err != nil || !reflect.DeepEqual(got, []any{int64(1)}) {
+		t.Fatalf("open channel: got %v, %v", got, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("open channel: returned after %s", elapsed)
+	}
+
+	_, err = Chan("f", time.Tick(time.Millisecond), 3, time.Second, "error", "number")
+	if err == nil || !strings.Contains(err.Error(), "more than 3 elements") {
+		t.Fatalf("endless channel: got %v", err)
+	}
+}
+
+func TestFloat(t *testing.T) {
+	if v, err := Float("f", 1.5, "error"); err != nil || v != 1.5 {
+		t.Errorf("finite: got %v, %v", v, err)
+	}
+
+	tests := []struct {
+		policy string
+		want   any
+		err    bool
+	}{
+		{policy: "error", err: true},
+		{policy: "null", want: nil},
+		{policy: "string", want: "+Inf"},
+	}
+
+	for _, tt := range tests {
+		got, err := Float("f", math.Inf(1), tt.policy)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", tt.policy, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %v, %v, want %v", tt.policy, got, err, tt.want)
+		}
+	}
+
+	if v, err := Float("f", math.NaN(), "string"); err != nil || v != "NaN" {
+		t.Errorf("NaN: got %v, %v", v, err)
+	}
+}
+
+func TestBigUint(t *testing.T) {
+	if v, _ := BigUint(uint64(math.MaxUint64)); v != "18446744073709551615" {
+		t.Errorf("MaxUint64: got %#v", v)
+	}
+	if v, _ := BigUint(uint64(42)); v != uint64(42) {
+		t.Errorf("42: got %#v", v)
+	}
+}
+
+func TestNormaliseStruct(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Point struct {
+		Base
+		X     float64
+		Tag   string `json:"tag,omitempty"`
+		Note  string `json:",omitempty"`
+		Count int64  `json:"count,string"`
+		Label string `json:"label,string"`
+		Dash  int    `json:"-,"`
+		Skip  int    `json:"-"`
+		Zero  *int   `json:"zero,omitzero"`
+	}
+
+	v, err := Normalise("makepoint", Point{Base: Base{ID: 7}, X: 1.5, Count: 3, Label: "a", Dash: 1, Skip: 2}, "error", "number")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"id": int64(7), "X": 1.5, "count": "3", "label": `"a"`, "-": int64(1)}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v, want %#v", v, want)
+	}
+}
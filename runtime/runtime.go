@@ -0,0 +1,16 @@
+// Package runtime holds the conversions and wrappers shared by the
+// bloblang plugins mod2blob generates: converting bloblang params to
+// Go args and Go results back to bloblang values, recovering from
+// panics, guarding calls with limits and caching results. Generated
+// code imports it as mod2blob, so a fix here reaches every generated
+// module without regenerating it.
+//
+// The Parse and Format funcs are the built-in adapters of common
+// standard library types, converting params to them and results from
+// them. Results are rendered as strings, with zero values becoming
+// null.
+package runtime
+
+// Function is the function a bloblang plugin constructor returns,
+// bloblang.Function without importing benthos.
+type Function = func() (any, error)